@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+)
+
+var capabilitiesCmd = &cobra.Command{
+	Use:   "capabilities",
+	Short: "Show the server's capabilities",
+	Long:  "Query the server's capabilities endpoint and print the supported features as JSON",
+	Args:  cobra.NoArgs,
+	Run:   runCapabilities,
+}
+
+func init() {
+	capabilitiesCmd.Flags().StringVar(&configFile, "config", "", "Path to config file")
+
+	rootCmd.AddCommand(capabilitiesCmd)
+}
+
+func runCapabilities(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		logger.Errorf("%s", i18n.T("config.load_failed", "Failed to load config: %v", err))
+		os.Exit(1)
+	}
+
+	warnInsecureConfig(cfg)
+
+	if err := cfg.ApplyProfile(profileName); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if err := cfg.ApplyPreset(presetName); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	client := ocr.NewClientWithOptions(cfg, ocr.ClientOptions{UserAgent: userAgentFor(cfg), Insecure: cfg.Options.Insecure})
+	if !client.IsConfigured() {
+		logger.Errorf("%s", i18n.T("main.not_configured", "PaddleOCR is not configured."))
+		fmt.Fprintln(os.Stderr, i18n.T("main.configure_hint", "Run 'paddleocr-cli configure' to set up credentials."))
+		os.Exit(1)
+	}
+
+	capabilities, err := client.FetchCapabilities(cmd.Context())
+	if err != nil {
+		logger.Errorf("Failed to fetch capabilities: %v", err)
+		os.Exit(1)
+	}
+
+	jsonBytes, err := json.MarshalIndent(capabilities, "", "  ")
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(jsonBytes))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// NoColorEnvVar turns colors off when set to anything, following
+// https://no-color.org.
+const NoColorEnvVar = "NO_COLOR"
+
+// Values of --color.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+var (
+	colorMode string
+	noColor   bool
+)
+
+// applyColorFlags checks --color and --no-color and colors the logger's
+// prefixes when stderr is colored.
+func applyColorFlags() error {
+	switch colorMode {
+	case colorAuto, colorAlways, colorNever:
+	default:
+		return fmt.Errorf("invalid --color %q (valid: %s, %s, %s)", colorMode, colorAuto, colorAlways, colorNever)
+	}
+	if noColor && colorMode == colorAlways {
+		return fmt.Errorf("--no-color and --color always are mutually exclusive")
+	}
+	logger.SetColor(colorEnabled(os.Stderr))
+	return nil
+}
+
+// colorEnabled reports whether text written to f is colored: always with
+// --color always, never with --color never, --no-color or NO_COLOR, and
+// otherwise when f is a terminal. Only stderr is ever colored: stdout
+// carries results and command output that may be parsed.
+func colorEnabled(f *os.File) bool {
+	switch {
+	case colorMode == colorAlways:
+		return true
+	case colorMode == colorNever || noColor || os.Getenv(NoColorEnvVar) != "":
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
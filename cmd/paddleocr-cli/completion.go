@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/log"
+)
+
+// completionShells lists the shells completion scripts are generated for.
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionInstall describes where each shell's script goes.
+var completionInstall = map[string]string{
+	"bash": `# Current session:
+source <(paddleocr-cli completion bash)
+# Every session (needs the bash-completion package):
+paddleocr-cli completion bash > ~/.local/share/bash-completion/completions/paddleocr-cli`,
+	"zsh": `# Every session (compinit must be enabled in ~/.zshrc):
+paddleocr-cli completion zsh > "${fpath[1]}/_paddleocr-cli"
+# Then start a new shell.`,
+	"fish": `# Every session:
+paddleocr-cli completion fish > ~/.config/fish/completions/paddleocr-cli.fish`,
+	"powershell": `# Current session:
+paddleocr-cli completion powershell | Out-String | Invoke-Expression
+# Every session: add the line above to your $PROFILE.`,
+}
+
+var noDescriptions bool
+
+var completionCmd = &cobra.Command{
+	Use:   "completion {bash|zsh|fish|powershell|install}",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a completion script for your shell and write it to stdout.
+Run 'paddleocr-cli completion install' to see where to put it.
+
+Completions cover subcommands, flags, file paths, and values for --scope,
+--format, --log-level and --profile (profile names are read from the config
+files; completion never contacts the server).`,
+	Args: cobra.NoArgs,
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:       "install [SHELL]",
+	Short:     "Print where to install the completion script",
+	Long:      "Print how to install the completion script for SHELL, or for the shell in $SHELL when omitted",
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: completionShells,
+	Run:       runCompletionInstall,
+}
+
+func init() {
+	completionCmd.PersistentFlags().BoolVar(&noDescriptions, "no-descriptions", false, "Leave out completion descriptions")
+
+	for _, shell := range completionShells {
+		shell := shell
+		completionCmd.AddCommand(&cobra.Command{
+			Use:   shell,
+			Short: fmt.Sprintf("Generate the completion script for %s", shell),
+			Long:  fmt.Sprintf("Generate the completion script for %s.\n\n%s", shell, completionInstall[shell]),
+			Args:  cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				if err := writeCompletion(shell); err != nil {
+					logger.Errorf("%v", err)
+					os.Exit(1)
+				}
+			},
+		})
+	}
+	completionCmd.AddCommand(completionInstallCmd)
+
+	rootCmd.AddCommand(completionCmd)
+}
+
+// writeCompletion writes the completion script for shell to stdout.
+func writeCompletion(shell string) error {
+	switch shell {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(os.Stdout, !noDescriptions)
+	case "zsh":
+		if noDescriptions {
+			return rootCmd.GenZshCompletionNoDesc(os.Stdout)
+		}
+		return rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		return rootCmd.GenFishCompletion(os.Stdout, !noDescriptions)
+	default:
+		if noDescriptions {
+			return rootCmd.GenPowerShellCompletion(os.Stdout)
+		}
+		return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+}
+
+func runCompletionInstall(cmd *cobra.Command, args []string) {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	if len(args) > 0 {
+		shell = args[0]
+	}
+
+	help, ok := completionInstall[shell]
+	if !ok {
+		for _, name := range completionShells {
+			fmt.Printf("%s:\n%s\n\n", name, completionInstall[name])
+		}
+		return
+	}
+	fmt.Println(help)
+}
+
+// completeScopes offers the valid --scope values for shell completion.
+func completeScopes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return config.Scopes, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeFormats offers the valid --format values.
+func completeFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return config.OutputFormats, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLogLevels offers the valid --log-level values.
+func completeLogLevels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return log.LevelNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles offers the profile names from the config files. It never
+// prompts for a passphrase, so a config with an encrypted token and no
+// $PADDLEOCR_PASSPHRASE simply yields no names.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	config.PromptPassphrase = nil
+	applyConfigFlags() // completion skips PersistentPreRun
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePresets offers the preset names from the config files, like
+// completeProfiles.
+func completePresets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	config.PromptPassphrase = nil
+	applyConfigFlags() // completion skips PersistentPreRun
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.PresetNames(), cobra.ShellCompDirectiveNoFileComp
+}
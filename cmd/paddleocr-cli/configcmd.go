@@ -0,0 +1,411 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get and set individual configuration values",
+	Long: `Get and set individual configuration values using dotted keys.
+
+Without --scope or --file, get and list read the effective (merged)
+configuration, while set and unset write to the user config.
+
+Examples:
+  paddleocr-cli config list
+  paddleocr-cli config get paddleocr.server_url
+  paddleocr-cli config set defaults.timeout 300 -s project
+  paddleocr-cli config set profiles.prod.access_token TOKEN
+  paddleocr-cli config unset defaults.retries --file ./ci.yaml
+  paddleocr-cli config validate
+  paddleocr-cli config migrate --dry-run`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print a configuration value",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Set a configuration value",
+	Args:  cobra.ExactArgs(2),
+	Run:   runConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset KEY",
+	Short: "Remove a configuration value",
+	Args:  cobra.ExactArgs(1),
+	Run:   runConfigUnset,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configuration values",
+	Args:  cobra.NoArgs,
+	Run:   runConfigList,
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Merge legacy config files into the canonical location",
+	Long: `Find config files in legacy locations, show which keys would be merged
+into the canonical user config, and on confirmation merge them and rename
+the originals with a .bak suffix.
+
+Keys already set in the destination keep their value. Differing tokens are
+never chosen silently: you are asked which to keep, and without a terminal
+the migration fails.`,
+	Args: cobra.NoArgs,
+	Run:  runConfigMigrate,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [PATH]",
+	Short: "Check config files for errors",
+	Long: `Check config files for unknown keys, malformed server URLs, placeholder
+tokens, missing token files and out-of-range values.
+
+Validates PATH, or the file selected by --file or --scope, or otherwise
+every discovered config file. Exits non-zero if any error is found.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runConfigValidate,
+}
+
+// Config subcommand flags
+var (
+	configScope string
+	configPath  string
+	reveal      bool
+
+	validateJSON bool
+	migrateDry   bool
+	migrateYes   bool
+)
+
+func init() {
+	configCmd.PersistentFlags().StringVarP(&configScope, "scope", "s", "", "Config scope: user, project, or local")
+	configCmd.PersistentFlags().StringVar(&configPath, "file", "", "Config file to operate on")
+	configCmd.RegisterFlagCompletionFunc("scope", completeScopes)
+	configGetCmd.Flags().BoolVar(&reveal, "reveal", false, "Print secret values in full")
+	configListCmd.Flags().BoolVar(&reveal, "reveal", false, "Print secret values in full")
+	configValidateCmd.Flags().BoolVar(&validateJSON, "json", false, "Output results as JSON")
+	configMigrateCmd.Flags().BoolVar(&migrateDry, "dry-run", false, "Print the migration plan without changing any file")
+	configMigrateCmd.Flags().BoolVarP(&migrateYes, "yes", "y", false, "Apply without asking for confirmation")
+
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configListCmd, configValidateCmd, configMigrateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// targetPath returns the file selected by --file or --scope, or "" if
+// neither was given.
+func targetPath() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	if configScope == "" {
+		return "", nil
+	}
+	path, err := config.GetSavePath(configScope)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", i18n.Errorf("configure.no_project_root", "No project root found (no %s in parent paths)", config.DescribeProjectMarkers())
+	}
+	return path, err
+}
+
+// loadDocument loads the targeted file, or the effective merged
+// configuration when readOnly and no file was selected.
+func loadDocument(readOnly bool) (*config.Document, error) {
+	path, err := targetPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		if readOnly {
+			cfg, err := config.Load("")
+			if err != nil {
+				return nil, err
+			}
+			return config.NewDocument(cfg)
+		}
+		if path, err = config.GetSavePath("user"); err != nil {
+			return nil, err
+		}
+	}
+
+	return config.LoadDocument(path)
+}
+
+// displayValue masks secret values unless --reveal is given.
+func displayValue(key, value string) string {
+	if info, ok := config.LookupKey(key); ok && info.Secret && !reveal {
+		if info.Kind == reflect.Slice && value != "" {
+			return strings.Join(maskTokens(value), ",")
+		}
+		return maskToken(value)
+	}
+	return value
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) {
+	doc, err := loadDocument(true)
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	value, err := doc.Get(args[0])
+	if errors.Is(err, config.ErrKeyNotFound) {
+		logger.Errorf("%s", i18n.T("configcmd.not_set", "%s is not set", args[0]))
+		os.Exit(1)
+	}
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(displayValue(args[0], value))
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) {
+	key, value := args[0], args[1]
+
+	doc, err := loadDocument(false)
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	if _, ok := config.LookupKey(key); !ok {
+		logger.Warnf("%s is not a known configuration key", key)
+	}
+
+	if err := doc.Set(key, value); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if err := doc.Validate(); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	if err := doc.Save(); err != nil {
+		logger.Errorf("%s", i18n.T("configure.save_failed", "Failed to save config: %v", err))
+		os.Exit(1)
+	}
+	fmt.Printf("Set %s in %s\n", key, doc.Path)
+}
+
+func runConfigUnset(cmd *cobra.Command, args []string) {
+	doc, err := loadDocument(false)
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	if !doc.Unset(args[0]) {
+		logger.Errorf("%s", i18n.T("configcmd.not_set_in", "%s is not set in %s", args[0], doc.Path))
+		os.Exit(1)
+	}
+
+	if err := doc.Save(); err != nil {
+		logger.Errorf("%s", i18n.T("configure.save_failed", "Failed to save config: %v", err))
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %s from %s\n", args[0], doc.Path)
+}
+
+func runConfigList(cmd *cobra.Command, args []string) {
+	doc, err := loadDocument(true)
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	for _, kv := range doc.List() {
+		fmt.Printf("%s=%s\n", kv.Key, displayValue(kv.Key, kv.Value))
+	}
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) {
+	var paths []string
+	if len(args) == 1 {
+		paths = args
+	} else {
+		path, err := targetPath()
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		if path != "" {
+			paths = []string{path}
+		} else {
+			paths = config.FindConfigs()
+		}
+	}
+	if len(paths) == 0 {
+		logger.Errorf("%s", i18n.T("configure.no_configs", "No config files found."))
+		os.Exit(1)
+	}
+
+	if !printValidation(paths, validateJSON, false) {
+		os.Exit(1)
+	}
+}
+
+// printValidation validates the config files at paths and prints the
+// problems found, as JSON if asJSON is set. It reports whether all files
+// are valid; with strict, warnings count as failures too.
+func printValidation(paths []string, asJSON, strict bool) bool {
+	results := make([]*config.Validation, 0, len(paths))
+	valid := true
+	for _, path := range paths {
+		result, err := config.ValidateFile(path)
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		if strict && len(result.Problems) > 0 {
+			result.Valid = false
+		}
+		results = append(results, result)
+		valid = valid && result.Valid
+	}
+
+	if asJSON {
+		jsonBytes, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+	} else {
+		for _, result := range results {
+			status := "OK"
+			if !result.Valid {
+				status = "INVALID"
+			}
+			fmt.Printf("%s: %s\n", result.File, status)
+			for _, p := range result.Problems {
+				fmt.Printf("  %s: %s\n", p.Severity, p)
+			}
+		}
+	}
+	return valid
+}
+
+// migrateSymbols marks each plan action in the printed plan.
+var migrateSymbols = map[string]string{
+	config.MigrateAdd:      "+",
+	config.MigrateSame:     "=",
+	config.MigrateKeep:     "~",
+	config.MigrateConflict: "!",
+}
+
+// migrateNotes explains each plan action in the printed plan.
+var migrateNotes = map[string]string{
+	config.MigrateAdd:      "",
+	config.MigrateSame:     " (same value)",
+	config.MigrateKeep:     " (destination value kept)",
+	config.MigrateConflict: " (values differ)",
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) {
+	sources := config.LegacyFiles()
+	if len(sources) == 0 {
+		fmt.Println("No legacy config files found.")
+		return
+	}
+
+	dest, err := config.PlatformUserConfigPath()
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	var migrations []*config.Migration
+	for _, source := range sources {
+		m, err := config.PlanMigration(source, dest)
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		migrations = append(migrations, m)
+	}
+
+	fmt.Print("Migration plan:\n\n")
+	for _, m := range migrations {
+		fmt.Printf("  %s -> %s\n", m.Source, m.Destination)
+		for _, f := range m.Fields {
+			note := migrateNotes[f.Action]
+			if f.NewValue != "" {
+				note += fmt.Sprintf(" (relative path rewritten to %s)", f.NewValue)
+			}
+			fmt.Printf("    %s %s%s\n", migrateSymbols[f.Action], f.Key, note)
+		}
+		fmt.Printf("  Original will be renamed to %s\n\n", m.BackupPath())
+	}
+
+	if migrateDry {
+		return
+	}
+
+	interactive := stdinIsTerminal()
+	for _, m := range migrations {
+		for _, key := range m.Conflicts() {
+			if !interactive {
+				logger.Errorf("%s", i18n.T("configcmd.migrate_conflict", "%s differs between %s and %s; run interactively or reconcile the files by hand", key, m.Source, m.Destination))
+				os.Exit(1)
+			}
+			answer, err := readLine(fmt.Sprintf("%s differs. Keep the [d]estination value or use the [s]ource value? ", key))
+			if err != nil {
+				logger.Errorf("%v", err)
+				os.Exit(1)
+			}
+			switch strings.ToLower(answer) {
+			case "d", "destination":
+				m.Resolve(key, false)
+			case "s", "source":
+				m.Resolve(key, true)
+			default:
+				fmt.Fprintln(os.Stderr, "Aborted.")
+				os.Exit(1)
+			}
+		}
+	}
+
+	if !migrateYes {
+		if !interactive {
+			logger.Errorf("%s", i18n.T("configcmd.confirm_required", "Confirmation required; re-run with --yes or --dry-run"))
+			os.Exit(1)
+		}
+		answer, err := readLine("Proceed? [y/N] ")
+		if err != nil || !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+			fmt.Fprintln(os.Stderr, "Aborted.")
+			os.Exit(1)
+		}
+	}
+
+	for _, m := range migrations {
+		if err := m.Apply(); err != nil {
+			logger.Errorf("%s", i18n.T("configcmd.migrate_failed", "Failed to migrate %s: %v", m.Source, err))
+			os.Exit(1)
+		}
+		fmt.Printf("Migrated %s to %s (backup: %s)\n", m.Source, m.Destination, m.BackupPath())
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+)
+
+// effectiveSetting is one resolved setting in --config-print output.
+type effectiveSetting struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+	// Source is "flag --NAME", "env $NAME", "file PATH" or "default".
+	Source string `json:"source"`
+}
+
+// runConfigPrint prints the settings an OCR run would use, after merging
+// config files, the profile, environment variables and flags, with the
+// source of each, then exits.
+func runConfigPrint(cmd *cobra.Command) {
+	cfg := loadRunConfig(cmd)
+	settings := effectiveSettings(cmd, cfg)
+
+	// Only an explicit --json or --format json selects JSON here; the
+	// config's defaults.format is one of the settings being printed.
+	flags := cmd.Flags()
+	if jsonOutput && (flags.Changed("json") || flags.Changed("format")) {
+		output := map[string]interface{}{
+			"sources":  append([]string{}, cfg.Sources()...),
+			"settings": settings,
+		}
+		jsonBytes, err := marshalOutputJSON(output)
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+		return
+	}
+
+	fmt.Println("Config files (lowest precedence first):")
+	if len(cfg.Sources()) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, source := range cfg.Sources() {
+		fmt.Printf("  %s\n", source)
+	}
+	fmt.Println()
+	fmt.Println("Effective settings:")
+	for _, setting := range settings {
+		fmt.Printf("  %-16s %-28v %s\n", setting.Name, setting.Value, setting.Source)
+	}
+}
+
+// effectiveSettings resolves the run settings and where each came from.
+func effectiveSettings(cmd *cobra.Command, cfg *config.Config) []effectiveSetting {
+	flags := cmd.Flags()
+	fromFlag := func(name string) string {
+		if flags.Changed(name) {
+			return "flag --" + name
+		}
+		return "default"
+	}
+	// fromConfig is fromFlag, falling back to the config file that set key.
+	fromConfig := func(name, key string) string {
+		if flags.Changed(name) {
+			return "flag --" + name
+		}
+		if origin := cfg.Origin(key); origin != "" {
+			return "file " + origin
+		}
+		return "default"
+	}
+	fromFile := func(keys ...string) string {
+		for _, key := range keys {
+			if origin := cfg.Origin(key); origin != "" {
+				return "file " + origin
+			}
+		}
+		return "unset"
+	}
+
+	var settings []effectiveSetting
+	add := func(name string, value interface{}, source string) {
+		settings = append(settings, effectiveSetting{Name: name, Value: value, Source: source})
+	}
+
+	profile, profileSource := cfg.ActiveProfile(), "unset"
+	switch {
+	case profile == "":
+		profile = "(none)"
+	case profileName != "":
+		profileSource = "flag --profile"
+	case os.Getenv(config.ProfileEnvVar) != "":
+		profileSource = "env $" + config.ProfileEnvVar
+	default:
+		profileSource = fromFile("default_profile")
+	}
+	add("profile", profile, profileSource)
+	if preset := cfg.ActivePreset(); preset != "" {
+		add("preset", preset, "flag --preset")
+	}
+
+	serverSource := fromFile("paddleocr.server_urls", "paddleocr.server_url")
+	if len(serverURLs) > 0 {
+		serverSource = "flag --server-url"
+	}
+	servers := cfg.PaddleOCR.Servers()
+	if len(servers) == 0 {
+		add("server_url", "(not set)", serverSource)
+	} else {
+		add("server_url", strings.Join(servers, ", "), serverSource)
+	}
+	if cfg.PaddleOCR.BasePath != "" {
+		add("base_path", cfg.PaddleOCR.BasePath, fromFile("paddleocr.base_path"))
+	}
+
+	tokenSource := fromFile("paddleocr.access_tokens", "paddleocr.access_token", "paddleocr.access_token_encrypted", "paddleocr.access_token_file")
+	if tokenFile != "" {
+		tokenSource = "flag --token-file"
+	}
+	switch tokens := cfg.PaddleOCR.Tokens(); len(tokens) {
+	case 0:
+		add("access_token", maskToken(""), tokenSource)
+	case 1:
+		add("access_token", maskToken(tokens[0]), tokenSource)
+	default:
+		add("access_tokens", fmt.Sprintf("%d configured", len(tokens)), tokenSource)
+	}
+
+	uaSource := fromFile("paddleocr.user_agent")
+	if userAgent != "" {
+		uaSource = "flag --user-agent"
+	} else if uaSource == "unset" {
+		uaSource = "default"
+	}
+	add("user_agent", userAgentFor(cfg), uaSource)
+
+	add("format", outputFormat, fromConfig("format", "defaults.format"))
+	if flags.Changed("json") {
+		settings[len(settings)-1].Source = "flag --json"
+	}
+	add("timeout", fmt.Sprintf("%ds", timeout), fromConfig("timeout", "defaults.timeout"))
+	add("connect_timeout", fmt.Sprintf("%ds", connectTimeout), fromFlag("connect-timeout"))
+	add("retries", retries, fromConfig("retries", "defaults.retries"))
+	add("retry_budget", retryBudget.String(), fromFlag("retry-budget"))
+	add("concurrency", concurrency, fromConfig("concurrency", "defaults.concurrency"))
+	add("rate", rate, fromConfig("rate", "defaults.rate"))
+	add("orientation", orientation, fromConfig("orientation", "options.orientation"))
+	add("unwarp", unwarp, fromConfig("unwarp", "options.unwarp"))
+	add("chart", chart, fromConfig("chart", "options.chart"))
+	add("insecure", insecure, fromConfig("insecure", "options.insecure"))
+	add("multipart", multipartUp, fromFlag("multipart"))
+	add("compress", compress, fromFlag("compress"))
+	add("max_dimension", maxDimension, fromFlag("max-dimension"))
+	add("log_level", logLevel, fromFlag("log-level"))
+	return settings
+}
@@ -0,0 +1,669 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/crypt"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+)
+
+var configureCmd = &cobra.Command{
+	Use:   "configure",
+	Short: "Configure PaddleOCR credentials",
+	Long:  "Configure or view PaddleOCR API credentials",
+	Run:   runConfigure,
+}
+
+// Configure flags
+var (
+	token       string
+	serverURL   string
+	showConfig  bool
+	testConn    bool
+	locations   bool
+	scope       string
+	unsetField  string
+	deleteEmpty bool
+	promptTok   bool
+	fixPerms    bool
+	showJSON    bool
+	encryptTok  bool
+	totpSecret  string
+	testTOTP    bool
+)
+
+// unsetFields maps --unset values to the config fields they remove.
+var unsetFields = map[string][]string{
+	"token":      {"access_token", "access_token_encrypted", "access_token_file", "access_tokens"},
+	"server-url": {"server_url"},
+	"all":        {"server_url", "access_token", "access_token_encrypted", "access_token_file", "access_tokens", "totp_secret"},
+}
+
+func init() {
+	configureCmd.Flags().StringVar(&token, "token", "", "Set the access token")
+	configureCmd.Flags().StringVar(&tokenFile, "token-file", "", "Read the access token from a file (- for stdin)")
+	configureCmd.Flags().BoolVar(&promptTok, "prompt-token", false, "Prompt for the access token with echo disabled (reads a line from stdin when not a terminal)")
+	configureCmd.Flags().BoolVar(&encryptTok, "encrypt-token", false, "Store the token encrypted with a passphrase ($PADDLEOCR_PASSPHRASE or a prompt); without a new token, encrypts the one already in the file")
+	configureCmd.Flags().StringVar(&serverURL, "server-url", "", "Set the server URL")
+	configureCmd.Flags().StringVar(&totpSecret, "totp-secret", "", "Store a base32 TOTP secret, encrypted with a passphrase, for gateways that require a second factor")
+	configureCmd.Flags().BoolVar(&testTOTP, "test-totp", false, "Test the connection with the configured TOTP secret")
+	configureCmd.Flags().BoolVar(&showConfig, "show", false, "Show current configuration")
+	configureCmd.Flags().BoolVar(&showJSON, "json", false, "With --show, print the effective configuration as JSON with per-field sources")
+	configureCmd.Flags().BoolVar(&testConn, "test", false, "Test connection to the server")
+	configureCmd.Flags().BoolVar(&locations, "locations", false, "Show config file search locations")
+	configureCmd.Flags().StringVarP(&scope, "scope", "s", "user", "Installation scope: user, project, or local")
+	configureCmd.RegisterFlagCompletionFunc("scope", completeScopes)
+	configureCmd.Flags().BoolVar(&fixPerms, "fix-permissions", false, "Restrict all discovered config files to owner read/write (chmod 600)")
+	configureCmd.Flags().StringVar(&unsetField, "unset", "", "Remove stored credentials: token (including access_token_file), server-url, or all (also the TOTP secret)")
+	configureCmd.Flags().BoolVar(&deleteEmpty, "delete-empty", false, "With --unset, delete the config file if it becomes empty")
+
+	rootCmd.AddCommand(configureCmd)
+}
+
+func runConfigure(cmd *cobra.Command, args []string) {
+	if err := config.ValidateScope(scope); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	// Show config locations
+	if locations {
+		fmt.Printf("%s\n\n", i18n.T("configure.locations_title", "Configuration file search locations:"))
+		for _, loc := range config.GetConfigLocations() {
+			status := "[not found]"
+			if loc.Exists {
+				status = "[FOUND]"
+			}
+			fmt.Printf("  %-12s %s\n", status, loc.Description)
+			fmt.Printf("             %s\n\n", loc.Path)
+		}
+		if hint := config.LegacyConfigHint(); hint != "" {
+			fmt.Println(i18n.T("configure.hint", "Hint: %s", hint))
+		}
+		return
+	}
+
+	// Load current (merged) config
+	cfg, err := config.Load("")
+	if err != nil {
+		logger.Errorf("%s", i18n.T("config.load_failed", "Failed to load config: %v", err))
+		os.Exit(1)
+	}
+
+	if fixPerms {
+		runFixPermissions()
+		return
+	}
+
+	// Writes target an explicit --profile only; reads also honor the
+	// environment and default_profile.
+	if showConfig || testConn {
+		warnInsecureConfig(cfg)
+		if err := cfg.ApplyProfile(profileName); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		if err := cfg.ApplyPreset(presetName); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	// Show current config
+	if showConfig && showJSON {
+		if err := printConfigJSON(cfg); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if showConfig {
+		fmt.Printf("%s\n\n", i18n.T("configure.current_title", "Current configuration:"))
+		sources := cfg.Sources()
+		if len(sources) == 0 {
+			fmt.Println("  Config files: (none found)")
+		}
+		for i, source := range sources {
+			label := "  Config files:"
+			if i > 0 {
+				label = ""
+			}
+			fmt.Printf("%-15s %s\n", label, source)
+		}
+		fmt.Println()
+		serverDisplay := cfg.PaddleOCR.ServerURL
+		if serverDisplay == "" {
+			serverDisplay = "(not set)"
+		}
+		fmt.Printf("  Server URL:   %s%s\n", serverDisplay, originSuffix(cfg, "paddleocr.server_url"))
+		if urls := cfg.PaddleOCR.ServerURLs; len(urls) > 0 {
+			fmt.Printf("  Server URLs:  %s%s\n", strings.Join(urls, ", "), originSuffix(cfg, "paddleocr.server_urls"))
+		}
+		if tokens := cfg.PaddleOCR.AccessTokens; len(tokens) > 0 {
+			fmt.Printf("  Access tokens: %d configured%s\n", len(tokens), originSuffix(cfg, "paddleocr.access_tokens"))
+		} else {
+			fmt.Printf("  Access token: %s%s\n", maskToken(cfg.PaddleOCR.AccessToken), originSuffix(cfg, "paddleocr.access_token"))
+		}
+		if err := printDefaults(cfg); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		if names := cfg.ProfileNames(); len(names) > 0 {
+			fmt.Println()
+			fmt.Println("  Profiles:")
+			for _, name := range names {
+				marker := " "
+				if name == cfg.ActiveProfile() {
+					marker = "*"
+				}
+				profileURL := cfg.Profiles[name].ServerURL
+				if profileURL == "" {
+					profileURL = "(inherits server URL)"
+				}
+				fmt.Printf("    %s %-12s %s\n", marker, name, profileURL)
+			}
+		}
+		if hint := config.LegacyConfigHint(); hint != "" {
+			fmt.Printf("\n%s\n", i18n.T("configure.hint", "Hint: %s", hint))
+		}
+		return
+	}
+
+	if testTOTP {
+		runTestTOTP(cfg)
+		return
+	}
+
+	// Test connection
+	if testConn {
+		if !cfg.IsConfigured() {
+			logger.Errorf("%s", i18n.T("configure.need_credentials", "server_url and access_token must be configured first."))
+			fmt.Fprintln(os.Stderr, i18n.T("configure.credentials_hint", "Run: paddleocr-cli configure --server-url URL --token TOKEN"))
+			os.Exit(1)
+		}
+		fmt.Println(i18n.T("configure.testing", "Testing connection to PaddleOCR server..."))
+		client := ocr.NewClientWithOptions(cfg, ocr.ClientOptions{UserAgent: userAgentFor(cfg), Insecure: cfg.Options.Insecure})
+		success, message := client.TestConnection()
+		message = strings.ReplaceAll(message, "\n", "\n       ")
+		if success {
+			fmt.Printf("  %s %s\n", "[OK]", message)
+		} else {
+			fmt.Printf("  %s %s\n", "[FAILED]", message)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if (token != "" && tokenFile != "") || (promptTok && (token != "" || tokenFile != "")) {
+		logger.Errorf("%s", i18n.T("configure.token_flags_conflict", "--token, --token-file and --prompt-token are mutually exclusive"))
+		os.Exit(1)
+	}
+
+	if promptTok {
+		if token, err = readToken("Access token: "); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		if token == "" {
+			logger.Errorf("%s", i18n.T("configure.no_token", "No token entered"))
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, i18n.T("configure.token_entered", "Token: %s", maskToken(token)))
+	}
+
+	if tokenFile != "" {
+		if token, err = config.ReadTokenFile(tokenFile); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	// Update config
+	if token == "" && serverURL == "" && unsetField == "" && !encryptTok && totpSecret == "" {
+		fmt.Fprint(os.Stderr, i18n.T("configure.usage", `Usage: paddleocr-cli configure --server-url URL --token TOKEN [-s SCOPE]
+
+Options:
+  --server-url URL   Set the server URL (required)
+  --token TOKEN      Set the access token (required)
+  --token-file PATH  Read the access token from a file (- for stdin)
+  --prompt-token     Prompt for the access token without echoing it
+  --encrypt-token    Store the access token encrypted with a passphrase
+  --totp-secret KEY  Store an encrypted TOTP secret for a second factor
+  -s, --scope SCOPE  Installation scope (default: user)
+                     user    - user config directory (see --locations)
+                     project - project root (nearest %s)
+                     local   - current directory
+  --profile NAME     Write the settings into a named profile
+  --unset FIELD      Remove token, server-url, or all
+  --show             Show current configuration
+  --test             Test connection
+  --test-totp        Test connection with the TOTP code
+`, config.DescribeProjectMarkers()))
+		os.Exit(1)
+	}
+
+	// Determine save path based on scope
+	savePath, err := config.GetSavePath(scope)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Errorf("%s", i18n.T("configure.no_project_root", "No project root found (no %s in parent paths)", config.DescribeProjectMarkers()))
+		} else {
+			logger.Errorf("%v", err)
+		}
+		os.Exit(1)
+	}
+
+	if unsetField != "" {
+		runUnset(cfg, savePath)
+		return
+	}
+
+	// Only the file at the chosen scope is updated, so values inherited from
+	// other layers are not copied into it, and other keys and comments in it
+	// are kept.
+	doc, err := config.LoadDocument(savePath)
+	if err != nil {
+		logger.Errorf("%s", i18n.T("config.load_failed", "Failed to load config: %v", err))
+		os.Exit(1)
+	}
+
+	if totpSecret != "" && profileName != "" {
+		logger.Errorf("%s", i18n.T("configure.totp_profile_conflict", "--totp-secret applies to the top-level paddleocr section and cannot be used with --profile"))
+		os.Exit(1)
+	}
+
+	// The same passphrase encrypts both the token and the TOTP secret, so
+	// it is asked for at most once.
+	passphrase := sync.OnceValues(newPassphrase)
+
+	prefix := "paddleocr."
+	if profileName != "" {
+		prefix = "profiles." + profileName + "."
+	}
+	if serverURL != "" {
+		if err := doc.Set(prefix+"server_url", serverURL); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+	}
+	if encryptTok {
+		if err := setEncryptedToken(doc, prefix, token, passphrase); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+	} else if token != "" {
+		if err := doc.Set(prefix+"access_token", token); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		doc.Unset(prefix + "access_token_encrypted")
+		doc.Unset(prefix + "access_tokens")
+	}
+	if totpSecret != "" {
+		if err := setTOTPSecret(doc, totpSecret, passphrase); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := doc.Save(); err != nil {
+		logger.Errorf("%s", i18n.T("configure.save_failed", "Failed to save config: %v", err))
+		os.Exit(1)
+	}
+
+	if profileName != "" {
+		fmt.Println(i18n.T("configure.profile_saved", "Profile %q saved to: %s", profileName, savePath))
+		return
+	}
+	fmt.Println(i18n.T("configure.saved", "Configuration saved to: %s", savePath))
+}
+
+// setEncryptedToken stores token, or the plaintext token already under
+// prefix, as access_token_encrypted and removes the plaintext entry.
+func setEncryptedToken(doc *config.Document, prefix, token string, newPassphrase func() (string, error)) error {
+	if token == "" {
+		token, _ = doc.Get(prefix + "access_token")
+	}
+	if token == "" {
+		return i18n.Errorf("configure.no_token_to_encrypt", "No token to encrypt: pass --token, --token-file or --prompt-token")
+	}
+
+	passphrase, err := newPassphrase()
+	if err != nil {
+		return err
+	}
+	encrypted, err := crypt.Encrypt(token, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := doc.Set(prefix+"access_token_encrypted", encrypted); err != nil {
+		return err
+	}
+	doc.Unset(prefix + "access_token")
+	doc.Unset(prefix + "access_tokens")
+	return nil
+}
+
+// setTOTPSecret stores secret encrypted as paddleocr.totp_secret.
+func setTOTPSecret(doc *config.Document, secret string, newPassphrase func() (string, error)) error {
+	if _, err := ocr.TOTPCode(secret); err != nil {
+		return i18n.Errorf("configure.invalid_totp_secret", "Invalid TOTP secret: %v", err)
+	}
+	passphrase, err := newPassphrase()
+	if err != nil {
+		return err
+	}
+	encrypted, err := crypt.Encrypt(secret, passphrase)
+	if err != nil {
+		return err
+	}
+	return doc.Set("paddleocr.totp_secret", encrypted)
+}
+
+// runTestTOTP tests the connection with the current TOTP code appended to
+// the access token, to check the secret and the server's clock agree.
+func runTestTOTP(cfg *config.Config) {
+	if cfg.PaddleOCR.TOTPSecret == "" {
+		logger.Errorf("%s", i18n.T("configure.no_totp_secret", "No totp_secret is configured."))
+		fmt.Fprintln(os.Stderr, i18n.T("configure.totp_hint", "Run: paddleocr-cli configure --totp-secret SECRET"))
+		os.Exit(1)
+	}
+	code, err := ocr.TOTPCode(cfg.PaddleOCR.TOTPSecret)
+	if err != nil {
+		logger.Errorf("%s", i18n.T("configure.invalid_totp_secret", "Invalid TOTP secret: %v", err))
+		os.Exit(1)
+	}
+	if !cfg.IsConfigured() {
+		logger.Errorf("%s", i18n.T("configure.need_credentials", "server_url and access_token must be configured first."))
+		os.Exit(1)
+	}
+
+	fmt.Println(i18n.T("configure.testing_totp", "Testing connection with TOTP code %s...", code))
+	client := ocr.NewClientWithOptions(cfg, ocr.ClientOptions{UserAgent: userAgentFor(cfg), Insecure: cfg.Options.Insecure})
+	success, message := client.TestConnection()
+	message = strings.ReplaceAll(message, "\n", "\n       ")
+	if !success {
+		fmt.Printf("  %s %s\n", "[FAILED]", message)
+		os.Exit(1)
+	}
+	fmt.Printf("  %s %s\n", "[OK]", i18n.T("configure.totp_accepted", "TOTP code accepted: %s", message))
+}
+
+// runFixPermissions tightens the permissions of all discovered config files.
+func runFixPermissions() {
+	paths := config.FindConfigs()
+	if len(paths) == 0 {
+		fmt.Println(i18n.T("configure.no_configs", "No config files found."))
+		return
+	}
+
+	failed := false
+	for _, path := range paths {
+		changed, err := config.FixPermissions(path)
+		switch {
+		case err != nil:
+			logger.Errorf("%s", i18n.T("configure.chmod_failed", "Failed to fix permissions on %s: %v", path, err))
+			failed = true
+		case changed:
+			fmt.Println(i18n.T("configure.fixed_permissions", "Fixed permissions: %s (now 600)", path))
+		default:
+			fmt.Println(i18n.T("configure.already_private", "Already private:   %s", path))
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runUnset removes credentials from the config file at savePath, or from the
+// --profile section in it. Values that do not come from that file are left
+// alone and reported.
+func runUnset(cfg *config.Config, savePath string) {
+	fields, ok := unsetFields[unsetField]
+	if !ok {
+		logger.Errorf("%s", i18n.T("configure.invalid_unset", "Invalid --unset value %q (use token, server-url, or all)", unsetField))
+		os.Exit(1)
+	}
+
+	prefix := "paddleocr."
+	if profileName != "" {
+		prefix = "profiles." + profileName + "."
+	}
+
+	doc, err := config.LoadDocument(savePath)
+	if err != nil {
+		logger.Errorf("%s", i18n.T("config.load_failed", "Failed to load config: %v", err))
+		os.Exit(1)
+	}
+	effective, err := config.NewDocument(cfg)
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	var removed, notSet []string
+	for _, field := range fields {
+		key := prefix + field
+		if _, ok := config.LookupKey(key); !ok {
+			// Profiles have no access_tokens or totp_secret.
+			continue
+		}
+		if doc.Unset(key) {
+			removed = append(removed, key)
+			continue
+		}
+
+		// A token read from access_token_file or access_token_encrypted
+		// has the same file as its origin; that key is removed above.
+		value, err := effective.Get(key)
+		if origin := cfg.Origin(key); err == nil && value != "" && origin != "" && origin != savePath {
+			fmt.Fprintln(os.Stderr, i18n.T("configure.set_elsewhere", "%s is not set in %s (it comes from %s; choose that scope with -s)", key, savePath, origin))
+		} else {
+			notSet = append(notSet, key)
+		}
+	}
+
+	if len(removed) == 0 {
+		for _, key := range notSet {
+			fmt.Fprintln(os.Stderr, i18n.T("configcmd.not_set_in", "%s is not set in %s", key, savePath))
+		}
+		os.Exit(1)
+	}
+
+	if deleteEmpty && doc.IsEmpty() {
+		if err := os.Remove(savePath); err != nil {
+			logger.Errorf("%s", i18n.T("configure.delete_failed", "Failed to delete config: %v", err))
+			os.Exit(1)
+		}
+	} else if err := doc.Save(); err != nil {
+		logger.Errorf("%s", i18n.T("configure.save_failed", "Failed to save config: %v", err))
+		os.Exit(1)
+	}
+
+	for _, key := range removed {
+		fmt.Println(i18n.T("configure.removed", "Removed %s from: %s", key, savePath))
+	}
+	if deleteEmpty && doc.IsEmpty() {
+		fmt.Println(i18n.T("configure.deleted_empty", "Deleted empty config file: %s", savePath))
+	}
+}
+
+// configField is one effective setting in configure --show --json output.
+type configField struct {
+	Value interface{} `json:"value"`
+	// Fingerprint identifies a secret value without revealing it.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Source      string `json:"source,omitempty"`
+	// Via is how Source came to be used (see configVia).
+	Via string `json:"via"`
+}
+
+// configVia reports how the value of key was selected: "flag" or "env" when
+// it comes from a profile chosen by --profile or $PADDLEOCR_PROFILE, a
+// preset chosen by --preset, or the user config of --config-dir or
+// $PADDLEOCR_CONFIG_DIR; "file" for other values loaded from a config file;
+// and "unset" for values from no file.
+func configVia(cfg *config.Config, key string) string {
+	source := cfg.Origin(key)
+	if source == "" {
+		return "unset"
+	}
+	switch cfg.OverlaidBy(key) {
+	case "preset":
+		return "flag"
+	case "profile":
+		if profileName != "" {
+			return "flag"
+		}
+		if os.Getenv(config.ProfileEnvVar) != "" {
+			return "env"
+		}
+		// Selected by default_profile.
+		return "file"
+	}
+	if userPath, err := config.UserConfigPath(); err == nil && source == userPath {
+		if configDir != "" {
+			return "flag"
+		}
+		if os.Getenv(config.ConfigDirEnvVar) != "" {
+			return "env"
+		}
+	}
+	return "file"
+}
+
+// configLocation is a config search location in configure --show --json
+// output.
+type configLocation struct {
+	Description string `json:"description"`
+	Path        string `json:"path"`
+	Exists      bool   `json:"exists"`
+}
+
+// printConfigJSON prints the effective configuration keyed by dotted key,
+// with the file each value came from. Secrets are masked and fingerprinted.
+func printConfigJSON(cfg *config.Config) error {
+	doc, err := config.NewDocument(cfg)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]configField)
+	for _, kv := range doc.List() {
+		value, err := doc.Get(kv.Key)
+		if err != nil {
+			return err
+		}
+
+		field := configField{Value: value, Source: cfg.Origin(kv.Key), Via: configVia(cfg, kv.Key)}
+		info, _ := config.LookupKey(kv.Key)
+		if info.Kind == reflect.Slice {
+			field.Value = strings.Split(value, ",")
+		}
+		if info.Secret && value != "" {
+			sum := sha256.Sum256([]byte(value))
+			field.Value = maskToken(value)
+			if info.Kind == reflect.Slice {
+				field.Value = maskTokens(value)
+			}
+			field.Fingerprint = "sha256:" + hex.EncodeToString(sum[:])
+		}
+		fields[kv.Key] = field
+	}
+
+	locations := []configLocation{}
+	for _, loc := range config.GetConfigLocations() {
+		locations = append(locations, configLocation{loc.Description, loc.Path, loc.Exists})
+	}
+
+	output := map[string]interface{}{
+		"fields":    fields,
+		"sources":   append([]string{}, cfg.Sources()...),
+		"locations": locations,
+	}
+	if name := cfg.ActiveProfile(); name != "" {
+		output["profile"] = name
+	}
+
+	jsonBytes, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(jsonBytes))
+	return nil
+}
+
+// maskTokens masks each token of a comma-separated list.
+func maskTokens(list string) []string {
+	var masked []string
+	for _, token := range strings.Split(list, ",") {
+		masked = append(masked, maskToken(token))
+	}
+	return masked
+}
+
+// printDefaults lists the effective run defaults and options, after any
+// profile and preset have been applied, with the file each one came from.
+func printDefaults(cfg *config.Config) error {
+	doc, err := config.NewDocument(cfg)
+	if err != nil {
+		return err
+	}
+
+	sections := []struct{ prefix, title string }{
+		{"defaults.", "Defaults:"},
+		{"options.", "Options:"},
+	}
+	for _, section := range sections {
+		var values []config.KeyValue
+		for _, kv := range doc.List() {
+			if strings.HasPrefix(kv.Key, section.prefix) {
+				values = append(values, kv)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		fmt.Println()
+		fmt.Println("  " + section.title)
+		for _, kv := range values {
+			fmt.Printf("    %-12s %s%s\n", strings.TrimPrefix(kv.Key, section.prefix), kv.Value, originSuffix(cfg, kv.Key))
+		}
+	}
+	return nil
+}
+
+// maskToken hides all but the tail of an access token, always keeping at
+// least four characters hidden and showing at most eight. Tokens too short
+// to show any of are reported by length only.
+func maskToken(token string) string {
+	switch n := len(token); {
+	case n == 0:
+		return "(not set)"
+	case n <= 4:
+		return fmt.Sprintf("(set, %d chars)", n)
+	default:
+		return "***" + token[n-min(n-4, 8):]
+	}
+}
+
+// originSuffix returns a " (from PATH)" annotation for a config key.
+func originSuffix(cfg *config.Config, key string) string {
+	if origin := cfg.Origin(key); origin != "" {
+		return fmt.Sprintf("  (from %s)", origin)
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaskToken(t *testing.T) {
+	const token = "abcdefghijkl"
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "(not set)"},
+		{1, "(set, 1 chars)"},
+		{2, "(set, 2 chars)"},
+		{3, "(set, 3 chars)"},
+		{4, "(set, 4 chars)"},
+		{5, "***e"},
+		{6, "***ef"},
+		{7, "***efg"},
+		{8, "***efgh"},
+		{9, "***efghi"},
+		{10, "***efghij"},
+		{11, "***efghijk"},
+		{12, "***efghijkl"},
+	}
+	for _, tt := range tests {
+		got := maskToken(token[:tt.n])
+		if got != tt.want {
+			t.Errorf("maskToken(%d chars) = %q, want %q", tt.n, got, tt.want)
+		}
+		if tt.n > 0 && got == "(not set)" {
+			t.Errorf("maskToken(%d chars) reports a set token as not set", tt.n)
+		}
+		if shown := strings.TrimPrefix(got, "***"); got != shown && len(shown) > tt.n-4 {
+			t.Errorf("maskToken(%d chars) = %q shows more than all but four characters", tt.n, got)
+		}
+	}
+}
+
+func TestMaskTokens(t *testing.T) {
+	got := maskTokens("abcdefgh,xyz")
+	want := []string{"***efgh", "(set, 3 chars)"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("maskTokens() = %q, want %q", got, want)
+	}
+}
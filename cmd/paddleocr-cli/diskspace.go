@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/platform"
+)
+
+// errLowDiskSpace is wrapped by checkFreeSpace errors, so writers abort
+// instead of carrying on with the next file.
+var errLowDiskSpace = errors.New("not enough free disk space")
+
+// checkFreeSpace returns an error wrapping errLowDiskSpace when the volume
+// holding path has less than --min-free-space MB available. Volumes whose
+// free space cannot be measured are not checked.
+func checkFreeSpace(path string) error {
+	if minFreeSpace <= 0 {
+		return nil
+	}
+	free, err := platform.FreeSpace(path)
+	if err != nil {
+		logger.Debugf("Cannot measure free space for %s: %v", path, err)
+		return nil
+	}
+	if free < uint64(minFreeSpace)<<20 {
+		return fmt.Errorf("%w: %s available for %s, below --min-free-space %d MB", errLowDiskSpace, formatSize(int64(free)), path, minFreeSpace)
+	}
+	return nil
+}
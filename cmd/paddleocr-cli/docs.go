@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var docsCmd = &cobra.Command{
+	Use:    "docs",
+	Short:  "Generate reference documentation",
+	Long:   "Generate man pages or a Markdown reference for paddleocr-cli and all its subcommands",
+	Hidden: true,
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man DIR",
+	Short: "Write section 1 man pages to DIR",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		header := &doc.GenManHeader{
+			Title:   "PADDLEOCR-CLI",
+			Section: "1",
+			Source:  "paddleocr-cli " + version,
+			Manual:  "PaddleOCR CLI Manual",
+		}
+		writeDocs(args[0], func(dir string) error {
+			return doc.GenManTree(rootCmd, header, dir)
+		})
+	},
+}
+
+var docsMarkdownCmd = &cobra.Command{
+	Use:   "markdown DIR",
+	Short: "Write a Markdown reference to DIR",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		writeDocs(args[0], func(dir string) error {
+			return doc.GenMarkdownTree(rootCmd, dir)
+		})
+	},
+}
+
+func init() {
+	docsCmd.AddCommand(docsManCmd, docsMarkdownCmd)
+	rootCmd.AddCommand(docsCmd)
+}
+
+// writeDocs creates dir and generates documentation into it. The generation
+// date is left out so the output is reproducible.
+func writeDocs(dir string, generate func(dir string) error) {
+	rootCmd.DisableAutoGenTag = true
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Errorf("Failed to create directory: %v", err)
+		os.Exit(1)
+	}
+	if err := generate(dir); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+	logger.Infof("Documentation written to: %s", dir)
+}
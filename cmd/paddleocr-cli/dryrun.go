@@ -0,0 +1,271 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/batch"
+	"github.com/Explorer1092/paddleocr_cli/internal/fileutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+)
+
+// planItem is one input of a --dry-run plan.
+type planItem struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type,omitempty"`
+	Size int64  `json:"size"`
+	// Upload estimates the request body size: the file itself with
+	// --multipart, otherwise its base64 encoding.
+	Upload int64  `json:"upload_bytes"`
+	Output string `json:"output,omitempty"`
+	// Action is what happens to the output: write, overwrite, skip,
+	// backup, split, print or copy.
+	Action string `json:"action,omitempty"`
+	// Status is "skipped" for an input that is not sent at all, with
+	// --skip-existing or --skip-unchanged, and Reason says why.
+	Status   string   `json:"status,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Rejected string   `json:"rejected,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	digest string
+}
+
+// runPlan is the --dry-run report, printed as a table or with --json as a
+// manifest.
+type runPlan struct {
+	Files    []planItem `json:"files"`
+	Rejected int        `json:"rejected"`
+	Skipped  int        `json:"skipped"`
+	Upload   int64      `json:"upload_bytes"`
+}
+
+// runDryRun discovers the files a run over filePath would process, works
+// out their output paths and what happens to existing files, and prints
+// the plan without contacting the server. It exits non-zero when any input
+// would be rejected.
+func runDryRun(filePath string, dataURI *ocr.DataURI, info os.FileInfo) {
+	plan := &runPlan{}
+	switch {
+	case dataURI != nil:
+		plan.addData(filePath, dataURI)
+	case info != nil && info.IsDir():
+		files, err := dirFiles(filePath)
+		if err != nil {
+			plan.reject(filePath, err)
+			break
+		}
+		plan.addFiles(files, filePath)
+	case fileutil.IsArchive(filePath):
+		plan.addArchive(filePath)
+	case batch.IsDLQ(filePath):
+		files, err := batch.ReadDLQ(filePath)
+		if err != nil {
+			plan.reject(filePath, fmt.Errorf("Failed to read %s: %v", filePath, err))
+			break
+		}
+		for _, file := range files {
+			if fileutil.IsArchive(file) {
+				plan.addArchive(file)
+			} else {
+				plan.addFiles([]string{file}, file)
+			}
+		}
+	default:
+		plan.addFiles([]string{filePath}, filePath)
+	}
+	plan.resolveOutputs(filePath)
+
+	if jsonOutput {
+		data, err := marshalOutputJSON(plan)
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		plan.print()
+	}
+	if plan.Rejected > 0 {
+		os.Exit(1)
+	}
+}
+
+// addFiles adds files named relative to baseDir, inspecting each as the
+// client would before sending it.
+func (p *runPlan) addFiles(files []string, baseDir string) {
+	for i, name := range resultNames(files, baseDir) {
+		item := planItem{Name: name, Path: files[i]}
+		fileType, warnings, err := ocr.InspectFile(files[i])
+		if err != nil {
+			item.Rejected = err.Error()
+		} else {
+			info, _ := os.Stat(files[i])
+			item.Type = fileType.String()
+			item.Size = info.Size()
+			item.Upload = uploadEstimate(item.Size)
+			item.Warnings = warnings
+			if skipUnchanged {
+				item.digest, _ = fileDigest(files[i])
+			}
+		}
+		p.Files = append(p.Files, item)
+	}
+}
+
+// addArchive adds the files of an archive, extracted to a temporary
+// directory as a run would, or rejects the archive.
+func (p *runPlan) addArchive(archivePath string) {
+	tmpDir, err := os.MkdirTemp("", "paddleocr-cli-")
+	if err != nil {
+		p.reject(archivePath, fmt.Errorf("Failed to create temp directory: %v", err))
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files, err := archiveFiles(archivePath, tmpDir)
+	if err != nil {
+		p.reject(archivePath, err)
+		return
+	}
+	start := len(p.Files)
+	p.addFiles(files, tmpDir)
+	for i := start; i < len(p.Files); i++ {
+		p.Files[i].Path = archivePath
+	}
+}
+
+// addData adds a data URI input.
+func (p *runPlan) addData(name string, dataURI *ocr.DataURI) {
+	size := int64(len(dataURI.Data))
+	p.Files = append(p.Files, planItem{
+		Name:   name,
+		Path:   "(data URI)",
+		Type:   dataURI.FileType().String(),
+		Size:   size,
+		Upload: uploadEstimate(size),
+	})
+}
+
+// reject adds an input that cannot be processed at all.
+func (p *runPlan) reject(path string, err error) {
+	p.Files = append(p.Files, planItem{Name: path, Path: path, Rejected: err.Error()})
+}
+
+// resolveOutputs fills in each accepted file's output and action, and the
+// totals. An existing output in --on-exists error mode rejects the file.
+func (p *runPlan) resolveOutputs(inputPath string) {
+	var accepted []int
+	var results []fileResult
+	for i, item := range p.Files {
+		if item.Rejected == "" {
+			accepted = append(accepted, i)
+			results = append(results, fileResult{Name: item.Name})
+		}
+	}
+
+	var paths []string
+	switch {
+	case outputFile != "" && isOutputDir(outputFile) && splitHeading > 0:
+	case outputFile != "" && isOutputDir(outputFile):
+		paths = outputPaths(results, inputPath, outputFile)
+	case outputFile != "":
+		paths = make([]string, len(accepted))
+		for i := range paths {
+			paths[i] = outputFile
+		}
+	}
+
+	for n, i := range accepted {
+		item := &p.Files[i]
+		switch {
+		case paths != nil:
+			item.Output = paths[n]
+			item.Action = outputAction(paths[n])
+			if reason, err := skipReason(paths[n], item.digest); err != nil {
+				item.Rejected = i18n.T("output.exists", "Output file already exists: %s", paths[n])
+			} else if reason != "" {
+				item.Action, item.Status, item.Reason = "skip", "skipped", reason
+			}
+		case outputFile != "":
+			item.Output, item.Action = outputFile, "split"
+		case toClipboard:
+			item.Output, item.Action = "(clipboard)", "copy"
+		default:
+			item.Output, item.Action = "(stdout)", "print"
+		}
+	}
+
+	for _, item := range p.Files {
+		if item.Rejected != "" {
+			p.Rejected++
+			continue
+		}
+		if item.Status == "skipped" {
+			p.Skipped++
+			continue
+		}
+		p.Upload += item.Upload
+	}
+}
+
+// outputAction returns what writing path would do under --on-exists, or ""
+// when it would fail because the file exists.
+func outputAction(path string) string {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "write"
+	}
+	switch collisionMode() {
+	case fileutil.CollisionSkip:
+		return "skip"
+	case fileutil.CollisionBackup:
+		return "backup"
+	case fileutil.CollisionError:
+		return ""
+	}
+	return "overwrite"
+}
+
+// uploadEstimate returns the request body size for a file of size bytes:
+// the raw bytes with --multipart, otherwise their base64 encoding. Gzip
+// and downscaling, which can only make it smaller, are not accounted for.
+func uploadEstimate(size int64) int64 {
+	if multipartUp {
+		return size
+	}
+	return (size + 2) / 3 * 4
+}
+
+// print writes the plan as a table followed by its warnings and a summary.
+func (p *runPlan) print() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tTYPE\tSIZE\tUPLOAD\tOUTPUT\tACTION")
+	var warnings []string
+	for _, item := range p.Files {
+		if item.Rejected != "" {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\trejected: %s\n", item.Name, item.Rejected)
+			continue
+		}
+		action := item.Action
+		if item.Reason != "" {
+			action += " (" + item.Reason + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", item.Name, item.Type,
+			formatSize(item.Size), formatSize(item.Upload), item.Output, action)
+		for _, warning := range item.Warnings {
+			warnings = append(warnings, item.Name+": "+warning)
+		}
+	}
+	w.Flush()
+
+	if len(warnings) > 0 {
+		fmt.Printf("\nWarnings:\n  - %s\n", strings.Join(warnings, "\n  - "))
+	}
+	fmt.Printf("\n%d file(s), %d rejected, %d skipped, about %s to upload. Dry run: nothing was sent or written.\n",
+		len(p.Files), p.Rejected, p.Skipped, formatSize(p.Upload))
+}
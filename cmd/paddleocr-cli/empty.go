@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+)
+
+// emptyFiles lists the results dropped by --fail-on-empty.
+var emptyFiles []string
+
+// dropEmptyResults removes the empty results with --fail-on-empty, logging
+// and recording each one, and returns the others.
+func dropEmptyResults(results []fileResult) []fileResult {
+	if !failOnEmpty {
+		return results
+	}
+	kept := results[:0:0]
+	for _, r := range results {
+		if r.Result.Empty {
+			logger.Errorf("%s", i18n.T("batch.empty", "%s: no text recognized (below --min-chars %d)", r.Name, minChars))
+			emptyFiles = append(emptyFiles, r.Name)
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// addEmpty adds the results dropped by --fail-on-empty to a batch's JSON
+// output.
+func addEmpty(data map[string]interface{}) {
+	if len(emptyFiles) > 0 {
+		data["empty_files"] = emptyFiles
+		data["success"] = false
+	}
+}
+
+// exitIfEmpty ends a run in which --fail-on-empty dropped results with exit
+// code 4, after listing them when there were several files.
+func exitIfEmpty() {
+	if len(emptyFiles) == 0 {
+		return
+	}
+	if total := runFailures.files(); total > 1 {
+		logger.Errorf("%s", i18n.T("batch.empty_summary", "%d of %d file(s) returned no text:\n  - %s", len(emptyFiles), total, strings.Join(emptyFiles, "\n  - ")))
+	}
+	exit(4)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+)
+
+// Modes that stop a batch early, as reported in stopped_by.
+const (
+	stopFailFast    = "fail-fast"
+	stopMaxFailures = "max-failures"
+)
+
+// runFailures tracks the failed and unprocessed files of the current run.
+var runFailures failureTracker
+
+// failedFile is a file that failed after its retries, as listed in the
+// JSON output. Error is in the user's locale; ErrorID, when known, is the
+// stable message ID scripts can match on.
+type failedFile struct {
+	File    string `json:"file"`
+	Error   string `json:"error"`
+	ErrorID string `json:"error_id,omitempty"`
+}
+
+// failureTracker counts the files of a run and the ones that failed, and
+// stops the run once --fail-fast or --max-failures is reached. By default
+// (--continue-on-error) it never stops it.
+type failureTracker struct {
+	mu          sync.Mutex
+	total       int
+	failed      []failedFile
+	unprocessed int
+	stoppedBy   string
+}
+
+// failureLimit returns the number of failed files that stops the run, or 0
+// to carry on regardless.
+func failureLimit() (int, string) {
+	switch {
+	case failFast:
+		return 1, stopFailFast
+	case maxFailures > 0:
+		return maxFailures, stopMaxFailures
+	}
+	return 0, ""
+}
+
+// add counts n more files in the run.
+func (t *failureTracker) add(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total += n
+}
+
+// record adds a file that failed after its retries, with its error message
+// and message ID, and reports whether the run must now stop.
+func (t *failureTracker) record(name, message, id string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed = append(t.failed, failedFile{File: name, Error: message, ErrorID: id})
+	if limit, mode := failureLimit(); limit > 0 && len(t.failed) >= limit && t.stoppedBy == "" {
+		t.stoppedBy = mode
+	}
+	return t.stoppedBy != ""
+}
+
+// skip counts n files that were not processed because the run stopped.
+func (t *failureTracker) skip(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.unprocessed += n
+}
+
+// stopped returns the mode that stopped the run, or "".
+func (t *failureTracker) stopped() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stoppedBy
+}
+
+// files returns the number of files in the run.
+func (t *failureTracker) files() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.total
+}
+
+// count returns the number of failed files.
+func (t *failureTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.failed)
+}
+
+// any reports whether files failed or were left unprocessed.
+func (t *failureTracker) any() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.failed) > 0 || t.unprocessed > 0
+}
+
+// addTo adds the failures to a batch's JSON output.
+func (t *failureTracker) addTo(data map[string]interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.failed) > 0 {
+		data["failed"] = t.failed
+	}
+	if t.unprocessed > 0 {
+		data["unprocessed"] = t.unprocessed
+	}
+	if t.stoppedBy != "" {
+		data["stopped_by"] = t.stoppedBy
+	}
+	if len(t.failed) > 0 || t.unprocessed > 0 {
+		data["success"] = false
+	}
+}
+
+// summarize logs the failed files of a multi-file run as a single list,
+// and why it stopped early if it did. It reports whether it logged
+// anything.
+func (t *failureTracker) summarize() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.total < 2 || (len(t.failed) == 0 && t.unprocessed == 0) {
+		return false
+	}
+	if len(t.failed) > 0 {
+		lines := make([]string, len(t.failed))
+		for i, f := range t.failed {
+			lines[i] = f.File + ": " + f.Error
+		}
+		logger.Errorf("%s", i18n.T("batch.failed", "%d of %d file(s) failed:\n  - %s", len(t.failed), t.total, strings.Join(lines, "\n  - ")))
+	}
+	if t.stoppedBy != "" {
+		logger.Errorf("%s", i18n.T("batch.stopped", "Stopped by --%s; %d file(s) left unprocessed", t.stoppedBy, t.unprocessed))
+	}
+	return true
+}
+
+// exitCode returns the exit code for a run with failures: 3 if --fail-fast
+// or --max-failures stopped it, otherwise 1.
+func (t *failureTracker) exitCode() int {
+	if t.stopped() != "" {
+		return 3
+	}
+	return 1
+}
+
+// exitIfFailed ends a run that wrote its output with the failure exit code
+// when it was stopped early, or when files failed and were not recorded in
+// a --dlq file.
+func exitIfFailed() {
+	if runFailures.stopped() == "" && (runFailures.count() == 0 || dlqPath != "") {
+		return
+	}
+	runFailures.summarize()
+	exit(runFailures.exitCode())
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/jsonschema"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+)
+
+var jsonSchemaCmd = &cobra.Command{
+	Use:   "json-schema",
+	Short: "Print the JSON Schema of --json output",
+	Long:  "Print a JSON Schema describing the output of 'paddleocr-cli ocr --json', generated from the result types",
+	Args:  cobra.NoArgs,
+	Run:   runJSONSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(jsonSchemaCmd)
+}
+
+func runJSONSchema(cmd *cobra.Command, args []string) {
+	jsonBytes, err := json.MarshalIndent(outputSchema(), "", "  ")
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(jsonBytes))
+}
+
+// outputSchema describes the two shapes formatOutput produces: a single
+// DocumentOCRResult, or a batch listing one entry per successful input file
+// along with the files that failed, were left unprocessed or were skipped.
+func outputSchema() jsonschema.Schema {
+	g := jsonschema.NewGenerator()
+	document := g.Type(reflect.TypeOf(ocr.DocumentOCRResult{}))
+
+	g.Defs()["BatchResult"] = jsonschema.Schema{
+		"type": "object",
+		"properties": jsonschema.Schema{
+			"success":  jsonschema.Schema{"type": "boolean"},
+			"warnings": jsonschema.Schema{"type": "array", "items": jsonschema.Schema{"type": "string"}},
+			"failed": jsonschema.Schema{
+				"type": "array",
+				"items": jsonschema.Schema{
+					"type": "object",
+					"properties": jsonschema.Schema{
+						"file":     jsonschema.Schema{"type": "string"},
+						"error":    jsonschema.Schema{"type": "string"},
+						"error_id": jsonschema.Schema{"type": "string"},
+					},
+					"required": []string{"file", "error"},
+				},
+			},
+			"unprocessed": jsonschema.Schema{"type": "integer"},
+			"empty_files": jsonschema.Schema{"type": "array", "items": jsonschema.Schema{"type": "string"}},
+			"skipped": jsonschema.Schema{
+				"type": "array",
+				"items": jsonschema.Schema{
+					"type": "object",
+					"properties": jsonschema.Schema{
+						"file":   jsonschema.Schema{"type": "string"},
+						"output": jsonschema.Schema{"type": "string"},
+						"status": jsonschema.Schema{"type": "string", "enum": []string{"skipped"}},
+						"reason": jsonschema.Schema{"type": "string"},
+					},
+					"required": []string{"file", "output", "status", "reason"},
+				},
+			},
+			"stopped_by": jsonschema.Schema{"type": "string", "enum": []string{stopFailFast, stopMaxFailures}},
+			"files": jsonschema.Schema{
+				"type": "array",
+				"items": jsonschema.Schema{
+					"type": "object",
+					"properties": jsonschema.Schema{
+						"file":           jsonschema.Schema{"type": "string"},
+						"pages":          g.Type(reflect.TypeOf([]ocr.OCRResult{})),
+						"log_id":         jsonschema.Schema{"type": "string"},
+						"request_id":     jsonschema.Schema{"type": "string"},
+						"expected_pages": jsonschema.Schema{"type": "integer"},
+						"partial":        jsonschema.Schema{"type": "boolean"},
+						"empty":          jsonschema.Schema{"type": "boolean"},
+						"warnings":       jsonschema.Schema{"type": "array", "items": jsonschema.Schema{"type": "string"}},
+					},
+					"required": []string{"file", "pages"},
+				},
+			},
+		},
+		"required": []string{"success", "files"},
+	}
+
+	return jsonschema.Schema{
+		"$schema": jsonschema.Draft,
+		"title":   "paddleocr-cli JSON output",
+		"oneOf":   []jsonschema.Schema{document, jsonschema.Ref("BatchResult")},
+		"$defs":   g.Defs(),
+	}
+}
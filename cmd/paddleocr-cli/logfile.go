@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// secretFlags are the flags whose values are redacted in the log file's
+// command line.
+var secretFlags = []string{"--pdf-password"}
+
+// openLogFile opens path for appending, writes a banner with the version
+// and command line, and copies all log messages to it from now on.
+func openLogFile(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("Cannot open log file: %v", err)
+	}
+	fmt.Fprintf(f, "=== paddleocr-cli %s run started %s ===\n", version, time.Now().Format(time.RFC3339))
+	fmt.Fprintf(f, "Command: %s\n", strings.Join(redactArgs(os.Args), " "))
+	logger.SetFile(f)
+	return nil
+}
+
+// redactArgs returns args with the values of secretFlags replaced by
+// "****", both as "--flag value" and "--flag=value".
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		for _, flag := range secretFlags {
+			switch {
+			case redacted[i] == flag && i+1 < len(redacted):
+				i++
+				redacted[i] = "****"
+			case strings.HasPrefix(redacted[i], flag+"="):
+				redacted[i] = flag + "=****"
+			default:
+				continue
+			}
+			break
+		}
+	}
+	return redacted
+}
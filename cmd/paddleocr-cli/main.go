@@ -2,17 +2,33 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/spf13/cobra"
 
+	"github.com/Explorer1092/paddleocr_cli/internal/batch"
 	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/fileutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/format"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+	"github.com/Explorer1092/paddleocr_cli/internal/imageutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/lb"
+	"github.com/Explorer1092/paddleocr_cli/internal/log"
 	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+	"github.com/Explorer1092/paddleocr_cli/internal/pdfutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/platform"
 )
 
 var (
@@ -36,12 +52,30 @@ Examples:
   paddleocr-cli resume.pdf                    # OCR and print to stdout
   paddleocr-cli resume.pdf -o output.md       # OCR and save to file
   paddleocr-cli resume.pdf --json             # Output as JSON
+  paddleocr-cli resume.pdf --format json      # Same as --json
+  paddleocr-cli scans.zip -o output.md        # OCR every file in a ZIP archive
+  paddleocr-cli scans.tar.gz                  # OCR every file in a TAR archive
+  paddleocr-cli scans/ --recursive -o out/ --preserve-structure
+                                              # One output per file, mirroring scans/
+  paddleocr-cli "data:image/png;base64,..."   # OCR a base64 data URI
   paddleocr-cli configure                     # Configure credentials
   paddleocr-cli configure --show              # Show current config
-  paddleocr-cli configure --test              # Test connection`,
-	Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
-	Args:    cobra.MaximumNArgs(1),
+  paddleocr-cli configure --test              # Test connection
+  paddleocr-cli resume.pdf --profile staging  # Use a named config profile
+
+Exit status:
+  0    Success
+  1    Error, or files in a batch failed
+  2    A result is missing pages (--fail-on-partial)
+  3    A batch was stopped early (--fail-fast, --max-failures)
+  4    A document had no usable text (--fail-on-empty)
+  130  Interrupted by SIGINT or SIGTERM`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if configPrint {
+			runConfigPrint(cmd)
+			return
+		}
 		if len(args) == 0 {
 			cmd.Help()
 			return
@@ -50,88 +84,394 @@ Examples:
 	},
 }
 
-var configureCmd = &cobra.Command{
-	Use:   "configure",
-	Short: "Configure PaddleOCR credentials",
-	Long:  "Configure or view PaddleOCR API credentials",
-	Run:   runConfigure,
-}
-
 // OCR flags
 var (
-	outputFile    string
-	jsonOutput    bool
-	pageNum       int
-	noSeparator   bool
-	timeout       int
-	orientation   bool
-	unwarp        bool
-	chart         bool
-	quiet         bool
-	configFile    string
+	outputFile   string
+	jsonOutput   bool
+	pageNum      int
+	noSeparator  bool
+	timeout      int
+	orientation  bool
+	unwarp       bool
+	chart        bool
+	quiet        bool
+	configFile   string
+	recursive    bool
+	maxSize      int64
+	pdfPassword  string
+	multipartUp  bool
+	retries      int
+	concurrency  int
+	rate         float64
+	outputFormat string
+	forceHTTP2   bool
+	insecure     bool
+	debug        bool
+	toc          bool
+	compress     bool
+
+	preserveStructure bool
+	tokenFile         string
+	retryBudget       time.Duration
+	dlqPath           string
+	strictConfig      bool
+	failOnPartial     bool
+	splitHeading      int
+	respectRateLimit  bool
+	sendMetrics       bool
+	serverURLs        []string
+	unhealthyWindow   time.Duration
+	wrapWidth         int
+	autoTimeout       bool
+	throughputKB      int
+	connectTimeout    int
+	since             string
+	sinceTime         time.Time
+	logLevel          string
+	progressFD        int
+	maxDimension      int
+	jpegQuality       int
+	overwriteOutput   bool
+	skipOutput        bool
+	backupOutput      bool
+	configPrint       bool
+	imageBaseURL      string
+	jsonIndent        int
+	jsonCompact       bool
+	verbose           bool
+	logFormat         string
+	logFile           string
+	dedupePages       bool
+	dedupeThreshold   float64
+	toClipboard       bool
+	openOutput        bool
+	noProgress        bool
+	onExists          string
+	fileMode          string
+	outputFileMode    = fileutil.DefaultFileMode
+	minFreeSpace      int64
+	dryRun            bool
+	continueOnError   bool
+	failFast          bool
+	maxFailures       int
+	serverIndices     bool
+	correctionsFile   string
+	corrections       []format.Correction
+	skipExisting      bool
+	skipUnchanged     bool
+	forceRun          bool
+	printLogIDOnly    bool
+	failOnEmpty       bool
+	minChars          int
+	maxResponsePages  int
+	cropSpec          string
+	cropRect          *imageutil.Rect
 )
 
-// Configure flags
+// Global flags
 var (
-	token      string
-	serverURL  string
-	showConfig bool
-	testConn   bool
-	locations  bool
-	scope      string
+	profileName    string
+	presetName     string
+	userAgent      string
+	configDir      string
+	projectMarkers []string
 )
 
 func init() {
+	config.PromptPassphrase = promptPassphrase
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if err := applyColorFlags(); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		applyConfigFlags()
+		startUpdateCheck(cmd, args)
+	}
+	rootCmd.PersistentPostRun = finishUpdateCheck
+
+	// Global flags
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to use (default: $PADDLEOCR_PROFILE or default_profile)")
+	rootCmd.PersistentFlags().StringVar(&presetName, "preset", "", "Options preset to use from the config's presets (applied over the profile; flags still win)")
+	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", "", "User config directory to use instead of the platform one (default: $PADDLEOCR_CONFIG_DIR)")
+	rootCmd.PersistentFlags().StringArrayVar(&projectMarkers, "project-marker", nil, "Also treat a directory containing NAME (e.g. go.mod or pyproject.toml) as a project root; repeatable")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", colorAuto, "Color errors, warnings and progress: auto (when stderr is a terminal; stdout is never colored), always or never")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Never use colors (same as --color never or setting $NO_COLOR)")
+	rootCmd.PersistentFlags().StringVar(&userAgent, "user-agent", "", "User-Agent header (default: user_agent from config, else paddleocr-cli/VERSION with the commit)")
+
 	// OCR flags (on root command)
 	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path (default: stdout)")
 	rootCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output as JSON instead of markdown")
+	rootCmd.Flags().StringVar(&outputFormat, "format", "markdown", "Output format: markdown or json")
 	rootCmd.Flags().IntVar(&pageNum, "page", -1, "Extract only page N (0-indexed)")
 	rootCmd.Flags().BoolVar(&noSeparator, "no-separator", false, "Don't add page separators in markdown output")
+	rootCmd.Flags().IntVar(&wrapWidth, "wrap", 0, "Hard-wrap prose at N columns, leaving code, tables and links intact (markdown output)")
+	rootCmd.Flags().BoolVar(&toc, "toc", false, "Prepend a table of contents built from headings (markdown output)")
+	rootCmd.Flags().StringArrayVar(&serverURLs, "server-url", nil, "Server URL for this run; repeat to spread requests across replicas (overrides the config)")
+	rootCmd.Flags().DurationVar(&unhealthyWindow, "lb-unhealthy-window", lb.DefaultUnhealthyWindow, "How long a replica that failed to connect is skipped")
 	rootCmd.Flags().IntVar(&timeout, "timeout", 120, "Request timeout in seconds")
+	rootCmd.Flags().IntVar(&connectTimeout, "connect-timeout", 30, "Connection timeout in seconds, so unreachable servers fail fast while --timeout allows long reads")
+	rootCmd.Flags().BoolVar(&autoTimeout, "auto-timeout", false, "Raise the timeout for large files to their size divided by --throughput-estimate (never below --timeout)")
+	rootCmd.Flags().IntVar(&throughputKB, "throughput-estimate", 100, "Expected upload and processing throughput in KB/s, for --auto-timeout")
+	rootCmd.Flags().IntVar(&retries, "retries", 0, "Retry failed requests N times (connection errors, HTTP 429/5xx)")
+	rootCmd.Flags().DurationVar(&retryBudget, "retry-budget", 0, "Cap the total time spent retrying a file, e.g. 2m (retries until spent; --retries still limits attempts if set)")
+	rootCmd.Flags().BoolVar(&failOnPartial, "fail-on-partial", false, "Exit with code 2 if the server returned fewer pages than the document has")
+	rootCmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty", false, "Treat a document with less text than --min-chars as failed: leave it out of the output and exit with code 4")
+	rootCmd.Flags().IntVar(&minChars, "min-chars", 1, "Characters of text, not counting whitespace, images and HTML tags, below which a document is empty")
+	rootCmd.Flags().StringVar(&dlqPath, "dlq", "", "Append files that still fail after retries to this JSON Lines file and continue (pass the file as input to retry them)")
+	rootCmd.Flags().BoolVar(&respectRateLimit, "respect-rate-limit", false, "On HTTP 429, wait as long as Retry-After or X-RateLimit-Reset asks before retrying")
+	rootCmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of files processed in parallel for archives")
+	rootCmd.Flags().Float64Var(&rate, "rate", 0, "Maximum requests per second (0 = unlimited)")
 	rootCmd.Flags().BoolVar(&orientation, "orientation", false, "Enable document orientation classification")
 	rootCmd.Flags().BoolVar(&unwarp, "unwarp", false, "Enable document unwarping")
 	rootCmd.Flags().BoolVar(&chart, "chart", false, "Enable chart recognition")
 	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress messages")
 	rootCmd.Flags().StringVar(&configFile, "config", "", "Path to config file")
+	rootCmd.Flags().BoolVar(&strictConfig, "strict-config", false, "Fail on unknown keys in config files (see 'config validate')")
+	rootCmd.Flags().StringVar(&tokenFile, "token-file", "", "Read the access token from a file for this run (- for stdin)")
+	rootCmd.Flags().BoolVar(&recursive, "recursive", false, "Include subdirectories of input directories and archives")
+	rootCmd.Flags().StringVar(&since, "since", "", "Only process files in directories and archives modified within DURATION (e.g. 24h) or since an RFC3339 time")
+	rootCmd.Flags().IntVar(&splitHeading, "split-on-heading", 0, "With -o DIR/, write one markdown file per section, splitting at headings of level N (1 = #) or higher")
+	rootCmd.Flags().BoolVar(&preserveStructure, "preserve-structure", false, "With -o DIR/, mirror the input directory layout instead of flattening")
+	rootCmd.Flags().StringVar(&pdfPassword, "pdf-password", "", "Password for encrypted PDFs (decrypted in memory only)")
+	rootCmd.Flags().BoolVar(&multipartUp, "multipart", false, "Upload raw file bytes as multipart/form-data when the server supports it")
+	rootCmd.Flags().BoolVar(&compress, "compress", false, "Gzip the JSON request body when the server supports it")
+	rootCmd.Flags().BoolVar(&forceHTTP2, "http2", false, "Force HTTP/2 (h2c for http:// servers)")
+	rootCmd.Flags().BoolVar(&insecure, "insecure", false, "Skip TLS certificate verification, e.g. for a staging server with a self-signed certificate")
+	rootCmd.Flags().BoolVar(&sendMetrics, "metrics", false, "After the run, send anonymized timings (duration, page count, success, version) to metrics_url from the config")
+	rootCmd.Flags().BoolVar(&debug, "debug", false, "Print diagnostic messages to stderr (same as --log-level debug)")
+	rootCmd.Flags().StringVar(&logLevel, "log-level", "info", "Messages to print to stderr: debug (including HTTP traffic), info (progress), warn or error")
+	rootCmd.Flags().BoolVar(&verbose, "verbose", false, "Print diagnostic messages too (same as --log-level debug)")
+	rootCmd.Flags().StringVar(&logFormat, "log-format", log.FormatText, "Format of stderr messages: text, or json for one JSON object per line")
+	rootCmd.Flags().StringVar(&logFile, "log-file", "", "Append a full debug-level log of the run, with timestamps, to FILE regardless of the console level")
+	rootCmd.Flags().BoolVar(&noProgress, "no-progress", false, "Don't draw a progress bar for multi-file runs on a terminal; log a progress summary every 10s instead")
+	rootCmd.Flags().IntVar(&progressFD, "progress-fd", 0, "Write progress messages to file descriptor N instead of stderr, e.g. 3 with 3>progress.log")
+	rootCmd.Flags().IntVar(&maxDimension, "max-dimension", 0, "Downscale images larger than PX on either side and re-encode them as JPEG before upload (0 = off)")
+	rootCmd.Flags().StringVar(&cropSpec, "crop", "", "OCR only the rectangle X,Y,W,H (in pixels from the top-left corner) of image inputs, e.g. 100,200,800,400")
+	rootCmd.Flags().IntVar(&jpegQuality, "jpeg-quality", imageutil.DefaultJPEGQuality, "JPEG quality (1-100) for images downscaled by --max-dimension")
+	rootCmd.Flags().BoolVar(&overwriteOutput, "overwrite", false, "Overwrite existing output files (the default)")
+	rootCmd.Flags().BoolVar(&skipOutput, "skip", false, "Leave existing output files alone, skipping their inputs before OCR")
+	rootCmd.Flags().BoolVar(&backupOutput, "backup", false, "Rename existing output files to .bak before writing")
+	rootCmd.Flags().StringVar(&onExists, "on-exists", "", "What to do when an output file exists: overwrite (default), skip, backup or error; checked before OCR")
+	rootCmd.Flags().StringVar(&fileMode, "file-mode", "0644", "Permissions of output files and images, in octal (0400-0777), e.g. 0600 or 0640")
+	rootCmd.Flags().BoolVar(&configPrint, "config-print", false, "Print the effective settings, with where each comes from (flag, env, file or default), and exit")
+	rootCmd.Flags().StringVar(&imageBaseURL, "image-base-url", "", "Rewrite image references in the output to PREFIX/<image path>, e.g. a CDN path the images written by --split-on-heading will be served from")
+	rootCmd.Flags().IntVar(&jsonIndent, "json-indent", 2, "Spaces to indent JSON output with (0 = minified)")
+	rootCmd.Flags().BoolVar(&jsonCompact, "json-compact", false, "Print JSON output minified on one line (same as --json-indent 0)")
+	rootCmd.Flags().BoolVar(&dedupePages, "dedupe-pages", false, "Drop pages whose text repeats an earlier page of the same document, e.g. double-fed sheets (no effect with --page)")
+	rootCmd.Flags().Float64Var(&dedupeThreshold, "dedupe-threshold", 0, "With --dedupe-pages, also drop near-duplicates whose overlap of word runs with an earlier page is at least this ratio, e.g. 0.9 (0 = exact duplicates only)")
+	rootCmd.Flags().BoolVar(&toClipboard, "clipboard", false, "Copy the output to the system clipboard instead of printing it")
+	rootCmd.MarkFlagsMutuallyExclusive("clipboard", "output")
+	rootCmd.Flags().BoolVar(&openOutput, "open", false, "With -o, open the saved output in the default application afterwards (an output directory is opened in the file manager)")
+	rootCmd.Flags().Int64Var(&minFreeSpace, "min-free-space", 0, "With -o, abort if the output volume has less than this many MB free, checked before the run and before each file is written (0 = no check)")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the files that would be processed, their output paths and the upload size, without contacting the server or writing anything (--json for a manifest)")
+	rootCmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "Keep processing a batch after a file fails, then write the rest and exit 1 (the default)")
+	rootCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop a batch at the first file that fails after its retries, cancelling requests in flight (exit code 3)")
+	rootCmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Stop a batch once N files have failed after their retries (exit code 3; 0 = never)")
+	rootCmd.MarkFlagsMutuallyExclusive("continue-on-error", "fail-fast", "max-failures")
+	rootCmd.Flags().BoolVar(&serverIndices, "preserve-server-indices", false, "Set each page's page_index to the page number the server reports for it, when it does, instead of its position in the response")
+	rootCmd.Flags().StringVar(&correctionsFile, "corrections", "", "Apply the wrong=>right or /regexp/=>replacement rules in `FILE` to the recognized text, in order")
+	rootCmd.Flags().BoolVar(&skipExisting, "skip-existing", false, "With --output, skip inputs whose output file already exists (same as --on-exists skip)")
+	rootCmd.MarkFlagsMutuallyExclusive("overwrite", "skip", "backup", "on-exists", "skip-existing")
+	rootCmd.Flags().BoolVar(&skipUnchanged, "skip-unchanged", false, "With --output, skip inputs whose output exists and was written from the same content, as recorded (with this flag) in OUTPUT"+metaSuffix)
+	rootCmd.Flags().BoolVar(&forceRun, "force", false, "Process every input despite --skip-existing or --skip-unchanged")
+	rootCmd.Flags().BoolVar(&printLogIDOnly, "print-log-id-only", false, "Print only the server's log id for each file, including failed ones that returned one, instead of the result")
+	rootCmd.Flags().IntVar(&maxResponsePages, "max-response-pages", 10000, "Reject a server response listing more pages than this, as malformed (0 = no limit)")
+	rootCmd.Flags().Int64Var(&maxSize, "max-size", 500, "Maximum total extracted size of an archive in MB (0 = unlimited)")
 
-	// Configure flags
-	configureCmd.Flags().StringVar(&token, "token", "", "Set the access token")
-	configureCmd.Flags().StringVar(&serverURL, "server-url", "", "Set the server URL")
-	configureCmd.Flags().BoolVar(&showConfig, "show", false, "Show current configuration")
-	configureCmd.Flags().BoolVar(&testConn, "test", false, "Test connection to the server")
-	configureCmd.Flags().BoolVar(&locations, "locations", false, "Show config file search locations")
-	configureCmd.Flags().StringVarP(&scope, "scope", "s", "user", "Installation scope: user, project, or local")
-
-	rootCmd.AddCommand(configureCmd)
+	rootCmd.RegisterFlagCompletionFunc("format", completeFormats)
+	rootCmd.RegisterFlagCompletionFunc("log-level", completeLogLevels)
+	rootCmd.RegisterFlagCompletionFunc("on-exists", cobra.FixedCompletions(fileutil.CollisionModeNames(), cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{log.FormatText, log.FormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.RegisterFlagCompletionFunc("profile", completeProfiles)
+	rootCmd.RegisterFlagCompletionFunc("preset", completePresets)
 }
 
 func runOCR(cmd *cobra.Command, args []string) {
 	filePath := args[0]
 
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: File not found: %s\n", filePath)
+	if err := setLogLevel(); err != nil {
+		logger.Errorf("%v", err)
 		os.Exit(1)
 	}
 
-	// Load config
-	cfg, err := config.Load(configFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+	// Decode data URI inputs up front, otherwise check the file exists
+	var dataURI *ocr.DataURI
+	var info os.FileInfo
+	var err error
+	if ocr.IsDataURI(filePath) {
+		dataURI, err = ocr.ParseDataURI(filePath)
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		filePath = "data-uri" + dataURI.Ext
+	} else {
+		info, err = os.Stat(filePath)
+		if os.IsNotExist(err) {
+			logger.Errorf("%s", i18n.T("ocr.file_not_found", "File not found: %s", filePath))
+			os.Exit(1)
+		}
+	}
+
+	if since != "" {
+		if sinceTime, err = parseSince(since, time.Now()); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	if preserveStructure && (outputFile == "" || !isOutputDir(outputFile)) {
+		logger.Errorf("%s", i18n.T("main.requires_output_dir", "%s requires an output directory (-o DIR/)", "--preserve-structure"))
 		os.Exit(1)
 	}
 
-	client := ocr.NewClient(cfg)
+	cfg := loadRunConfig(cmd)
 
-	if !client.IsConfigured() {
-		fmt.Fprintln(os.Stderr, "Error: PaddleOCR is not configured.")
-		fmt.Fprintln(os.Stderr, "Run 'paddleocr-cli configure' to set up credentials.")
+	if maxDimension < 0 {
+		logger.Errorf("%s", i18n.T("main.flag_negative", "%s must not be negative", "--max-dimension"))
+		os.Exit(1)
+	}
+	if jpegQuality < 1 || jpegQuality > 100 {
+		logger.Errorf("%s", i18n.T("main.flag_range", "%s must be between %v and %v", "--jpeg-quality", 1, 100))
+		os.Exit(1)
+	}
+	if dedupeThreshold < 0 || dedupeThreshold > 1 {
+		logger.Errorf("%s", i18n.T("main.flag_range", "%s must be between %v and %v", "--dedupe-threshold", 0, 1))
+		os.Exit(1)
+	}
+	if correctionsFile != "" {
+		rules, err := format.LoadCorrections(correctionsFile)
+		if err != nil {
+			logger.Errorf("--corrections: %v", err)
+			os.Exit(1)
+		}
+		corrections = rules
+	}
+	if onExists != "" {
+		if _, err := fileutil.ParseCollisionMode(onExists); err != nil {
+			logger.Errorf("--on-exists: %v", err)
+			os.Exit(1)
+		}
+	}
+	// Fail before the request rather than after it when the single output
+	// file could not be written anyway.
+	if checkOutputs() && !isOutputDir(outputFile) {
+		if _, err := skipReason(outputFile, ""); err != nil {
+			logger.Errorf("%s", i18n.T("output.exists", "Output file already exists: %s", outputFile))
+			fmt.Fprintln(os.Stderr, i18n.T("output.exists_hint", "Use --on-exists overwrite, skip or backup to write it."))
+			os.Exit(1)
+		}
+	}
+	if mode, err := strconv.ParseUint(fileMode, 8, 32); err != nil || mode < 0400 || mode > 0777 {
+		logger.Errorf("%s", i18n.T("main.file_mode", "%s must be an octal mode between 0400 and 0777, got %q", "--file-mode", fileMode))
+		os.Exit(1)
+	} else {
+		outputFileMode = os.FileMode(mode)
+	}
+	if openOutput && outputFile == "" {
+		logger.Errorf("%s", i18n.T("main.flag_requires", "%s requires %s", "--open", "--output"))
+		os.Exit(1)
+	}
+	if printLogIDOnly && (outputFile != "" || toClipboard) {
+		logger.Errorf("%s", i18n.T("main.log_id_only_conflict", "--print-log-id-only cannot be used with --output or --clipboard"))
+		os.Exit(1)
+	}
+	if cropSpec != "" {
+		rect, err := imageutil.ParseRect(cropSpec)
+		if err != nil {
+			logger.Errorf("--crop: %v", err)
+			os.Exit(1)
+		}
+		cropRect = &rect
+	}
+	if maxResponsePages < 0 {
+		logger.Errorf("%s", i18n.T("main.flag_negative", "%s must not be negative", "--max-response-pages"))
+		os.Exit(1)
+	}
+	if minChars < 1 {
+		logger.Errorf("%s", i18n.T("main.flag_min", "%s must be at least %d", "--min-chars", 1))
+		os.Exit(1)
+	}
+	if maxFailures < 0 {
+		logger.Errorf("%s", i18n.T("main.flag_negative", "%s must not be negative", "--max-failures"))
+		os.Exit(1)
+	}
+	if minFreeSpace < 0 {
+		logger.Errorf("%s", i18n.T("main.flag_negative", "%s must not be negative", "--min-free-space"))
+		os.Exit(1)
+	}
+	if outputFile != "" {
+		if err := checkFreeSpace(outputFile); err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+	}
+	if jsonIndent < 0 {
+		logger.Errorf("%s", i18n.T("main.flag_negative", "%s must not be negative", "--json-indent"))
 		os.Exit(1)
 	}
 
-	// Perform OCR
-	if !quiet {
-		fmt.Fprintf(os.Stderr, "Processing: %s\n", filePath)
+	if autoTimeout && throughputKB <= 0 {
+		logger.Errorf("%s", i18n.T("main.flag_positive", "%s must be positive", "--throughput-estimate"))
+		os.Exit(1)
+	}
+
+	if splitHeading != 0 {
+		if splitHeading < 1 || splitHeading > 6 {
+			logger.Errorf("%s", i18n.T("main.flag_range", "%s must be between %v and %v", "--split-on-heading", 1, 6))
+			os.Exit(1)
+		}
+		if outputFile == "" || !isOutputDir(outputFile) {
+			logger.Errorf("%s", i18n.T("main.requires_output_dir", "%s requires an output directory (-o DIR/)", "--split-on-heading"))
+			os.Exit(1)
+		}
+		if jsonOutput {
+			logger.Errorf("%s", i18n.T("main.requires_markdown", "%s requires markdown output", "--split-on-heading"))
+			os.Exit(1)
+		}
+	}
+
+	if skipExisting || skipUnchanged {
+		if outputFile == "" {
+			logger.Errorf("%s", i18n.T("main.flag_requires", "%s requires %s", skipFlag(), "--output"))
+			os.Exit(1)
+		}
+		if splitHeading > 0 {
+			logger.Errorf("%s", i18n.T("main.flag_conflict", "%s cannot be used with %s", skipFlag(), "--split-on-heading"))
+			os.Exit(1)
+		}
+		// A combined output file would lose the skipped inputs.
+		if !isOutputDir(outputFile) && (dataURI != nil || (info != nil && info.IsDir()) || fileutil.IsArchive(filePath) || batch.IsDLQ(filePath)) {
+			logger.Errorf("%s", i18n.T("main.skip_single_input", "%s with an output file requires a single input file; use -o DIR/ for batches", skipFlag()))
+			os.Exit(1)
+		}
+	}
+	runInput = filePath
+
+	if dryRun {
+		runDryRun(filePath, dataURI, info)
+		return
+	}
+
+	clientOpts := ocr.ClientOptions{
+		ForceHTTP2:      forceHTTP2,
+		UserAgent:       userAgentFor(cfg),
+		ConnectTimeout:  time.Duration(connectTimeout) * time.Second,
+		UnhealthyWindow: unhealthyWindow,
+		Insecure:        insecure,
+	}
+	if insecure {
+		logger.Warnf("TLS certificate verification is disabled (--insecure or options.insecure)")
+	}
+	if logger.Enabled(log.LevelDebug) {
+		clientOpts.Logger = logger
+	}
+	client := ocr.NewClientWithOptions(cfg, clientOpts)
+
+	if !client.IsConfigured() {
+		logger.Errorf("%s", i18n.T("main.not_configured", "PaddleOCR is not configured."))
+		fmt.Fprintln(os.Stderr, i18n.T("main.configure_hint", "Run 'paddleocr-cli configure' to set up credentials."))
+		os.Exit(1)
 	}
 
 	opts := ocr.OCROptions{
@@ -139,175 +479,957 @@ func runOCR(cmd *cobra.Command, args []string) {
 		UseDocUnwarping:           unwarp,
 		UseChartRecognition:       chart,
 		Timeout:                   time.Duration(timeout) * time.Second,
+		PDFPassword:               pdfPassword,
+		Multipart:                 multipartUp,
+		Retries:                   retries,
+		RetryBudget:               retryBudget,
+		MaxDimension:              maxDimension,
+		JPEGQuality:               jpegQuality,
+		PreserveServerIndices:     serverIndices,
+		MinChars:                  minChars,
+		MaxPages:                  maxResponsePages,
+		Crop:                      cropRect,
+	}
+	if respectRateLimit {
+		opts.OnRateLimit = func(wait time.Duration) {
+			logger.Infof("%s", i18n.T("main.rate_limited", "Rate limited; waiting %s", wait.Round(time.Second)))
+		}
 	}
 
-	result := client.OCRFile(filePath, opts)
+	// Ctrl+C or SIGTERM cancels in-flight requests; a second signal kills
+	// the process as usual.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	ocrCtx = ctx
 
-	if !result.Success {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", result.ErrorMessage)
-		os.Exit(1)
+	if compress {
+		if client.SupportsFeature(ctx, ocr.FeatureGzip) {
+			opts.Compress = true
+		} else {
+			runWarnings.Warnf("server does not advertise gzip request support; sending uncompressed")
+		}
 	}
 
-	if !quiet {
-		fmt.Fprintf(os.Stderr, "OCR completed: %d page(s)\n", len(result.Pages))
+	reauth = newReauthenticator(client, cfg)
+
+	var results []fileResult
+	start := time.Now()
+	if dataURI != nil {
+		results, err = ocrDataURI(client, dataURI, filePath, opts)
+	} else if info != nil && info.IsDir() {
+		results, err = ocrDir(client, filePath, opts)
+	} else if fileutil.IsArchive(filePath) {
+		results, err = ocrArchive(client, filePath, opts)
+	} else if batch.IsDLQ(filePath) {
+		results, err = ocrDLQ(client, filePath, opts)
+	} else {
+		results, err = ocrFiles(client, []string{filePath}, filePath, "", opts)
+	}
+	if sendMetrics {
+		runMetrics = newMetricsReport(cfg, results, err == nil, time.Since(start))
+		defer runMetrics.wait()
+	}
+	if errors.Is(err, errInterrupted) {
+		logger.Errorf("%s", i18n.T("main.interrupted", "Interrupted"))
+		exit(130)
+	}
+	if err != nil {
+		runWarnings.summarize()
+		if !runFailures.summarize() {
+			logger.Errorf("%v", err)
+		} else if jsonOutput {
+			writeFailureManifest()
+		}
+		exit(runFailures.exitCode())
+	}
+	if printLogIDOnly {
+		runWarnings.summarize()
+		exitIfFailed()
+		exitIfPartial(results)
+		return
+	}
+	if len(results) == 0 && (len(skippedFiles) > 0 || conflictCount > 0) {
+		if len(skippedFiles) > 0 {
+			logger.Infof("%s", i18n.T("main.all_skipped", "Nothing to do: %d file(s) skipped", len(skippedFiles)))
+		}
+		runWarnings.summarize()
+		exitIfFailed()
+		if err := existingError(conflictCount); err != nil {
+			logger.Errorf("%v", err)
+			exit(1)
+		}
+		return
 	}
 
-	// Format output
-	var output string
-	if jsonOutput {
-		outputData := map[string]interface{}{
-			"success": true,
-			"pages":   result.Pages,
-			"log_id":  result.LogID,
+	// --page selects by the position in the server's response, so it is
+	// applied first: a single page has nothing to be a duplicate of.
+	if dedupePages && pageNum < 0 {
+		dropDuplicatePages(results, dedupeThreshold)
+	}
+	if len(corrections) > 0 {
+		applyCorrections(results)
+	}
+	if imageBaseURL != "" {
+		rewriteImageRefs(results, imageBaseURL)
+	}
+	if results = dropEmptyResults(results); len(results) == 0 {
+		runWarnings.summarize()
+		if jsonOutput {
+			writeFailureManifest()
 		}
-		jsonBytes, err := json.MarshalIndent(outputData, "", "  ")
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to marshal JSON: %v\n", err)
-			os.Exit(1)
+		exitIfFailed()
+		exitIfEmpty()
+	}
+
+	if outputFile != "" && isOutputDir(outputFile) {
+		write := func() error { return writeOutputDir(results) }
+		if splitHeading > 0 {
+			write = func() error { return writeSections(results, outputFile) }
 		}
-		output = string(jsonBytes)
-	} else {
-		// Markdown output
-		if pageNum >= 0 {
-			if pageNum < len(result.Pages) {
-				output = result.Pages[pageNum].Markdown
-			} else {
-				fmt.Fprintf(os.Stderr, "Error: Page %d not found (document has %d pages)\n", pageNum, len(result.Pages))
-				os.Exit(1)
-			}
-		} else if noSeparator {
-			var parts []string
-			for _, page := range result.Pages {
-				parts = append(parts, page.Markdown)
-			}
-			output = strings.Join(parts, "\n\n")
-		} else {
-			output = result.FullMarkdown()
+		if err := write(); err != nil {
+			logger.Errorf("%v", err)
+			exit(1)
 		}
+		openSavedOutput()
+		runMetrics.send()
+		runWarnings.summarize()
+		exitIfFailed()
+		exitIfEmpty()
+		exitIfPartial(results)
+		return
+	}
+
+	// Format output
+	output, err := formatOutput(results)
+	if err != nil {
+		logger.Errorf("%v", err)
+		exit(1)
 	}
 
 	// Write output
 	if outputFile != "" {
-		if err := os.WriteFile(outputFile, []byte(output), 0644); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Failed to write output: %v\n", err)
-			os.Exit(1)
+		written, err := writeOutputFile(outputFile, []byte(output))
+		if errors.Is(err, fileutil.ErrExists) {
+			fmt.Fprintln(os.Stderr, i18n.T("output.exists_hint", "Use --on-exists overwrite, skip or backup to write it."))
+			exit(1)
+		} else if err != nil {
+			logger.Errorf("%v", err)
+			exit(1)
 		}
-		if !quiet {
-			fmt.Fprintf(os.Stderr, "Output saved to: %s\n", outputFile)
+		if written && len(results) == 1 {
+			writeOutputMeta(outputFile, results[0])
 		}
+		openSavedOutput()
+	} else if toClipboard {
+		if err := clipboard.WriteAll(output); err != nil {
+			logger.Errorf("%s", i18n.T("main.clipboard_failed", "Cannot copy to the clipboard: %v", err))
+			exit(1)
+		}
+		logger.Infof("%s", i18n.T("main.clipboard_copied", "Output copied to the clipboard"))
 	} else {
 		fmt.Println(output)
 	}
+	runMetrics.send()
+	runWarnings.summarize()
+	exitIfFailed()
+	exitIfEmpty()
+	exitIfPartial(results)
 }
 
-func runConfigure(cmd *cobra.Command, args []string) {
-	// Show config locations
-	if locations {
-		fmt.Println("Configuration file search locations:\n")
-		for _, loc := range config.GetConfigLocations() {
-			status := "[not found]"
-			if loc.Exists {
-				status = "[FOUND]"
-			}
-			fmt.Printf("  %-12s %s\n", status, loc.Description)
-			fmt.Printf("             %s\n\n", loc.Path)
-		}
+// writeFailureManifest writes the JSON output of a batch in which no file
+// succeeded, listing the failures, to -o or stdout. With an output
+// directory there is nowhere to put it.
+func writeFailureManifest() {
+	if outputFile != "" && isOutputDir(outputFile) {
 		return
 	}
+	output, err := formatOutput(nil)
+	if err != nil {
+		return
+	}
+	if outputFile != "" {
+		writeOutputFile(outputFile, []byte(output))
+		return
+	}
+	fmt.Println(output)
+}
+
+// applyConfigFlags applies the global flags that change where config files
+// are found: --config-dir and --project-marker.
+func applyConfigFlags() {
+	config.SetUserConfigDir(configDir)
+	config.AddProjectMarkers(projectMarkers...)
+}
 
-	// Load current config
-	configPath := config.FindConfig()
-	cfg, err := config.Load(configPath)
+// loadRunConfig loads the config for an OCR run and applies the profile,
+// --token-file, --server-url and the config's defaults to it.
+func loadRunConfig(cmd *cobra.Command) *config.Config {
+	load := config.Load
+	if strictConfig {
+		load = config.LoadStrict
+	}
+	cfg, err := load(configFile)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		logger.Errorf("%s", i18n.T("config.load_failed", "Failed to load config: %v", err))
+		os.Exit(1)
+	}
+
+	if !quiet {
+		warnInsecureConfig(cfg)
+	}
+
+	if err := cfg.ApplyProfile(profileName); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if err := cfg.ApplyPreset(presetName); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	if tokenFile != "" {
+		token, err := config.ReadTokenFile(tokenFile)
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		cfg.PaddleOCR.AccessToken = token
+		cfg.PaddleOCR.AccessTokens = nil
+	}
+
+	if len(serverURLs) > 0 {
+		cfg.PaddleOCR.ServerURLs = serverURLs
+	}
+
+	if err := applyDefaults(cmd, cfg.Defaults); err != nil {
+		logger.Errorf("%v", err)
 		os.Exit(1)
 	}
+	applyOptions(cmd, cfg.Options)
+	return cfg
+}
+
+// exitIfPartial exits with code 2 when --fail-on-partial is set and any
+// result is missing pages.
+func exitIfPartial(results []fileResult) {
+	if !failOnPartial {
+		return
+	}
+	for _, r := range results {
+		if r.Result.Partial {
+			exit(2)
+		}
+	}
+}
+
+// logger writes progress, warnings and diagnostics to stderr, filtered by
+// --log-level.
+var logger = log.New(os.Stderr, log.LevelInfo)
+
+// setLogLevel applies --log-level, raised to debug by --debug and lowered
+// to warn by --quiet when the level was left at info, along with
+// --log-format, --progress-fd and --log-file.
+func setLogLevel() error {
+	level, err := log.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	switch {
+	case debug || verbose:
+		level = log.LevelDebug
+	case quiet && level == log.LevelInfo:
+		level = log.LevelWarn
+	}
+	logger.SetLevel(level)
+	if err := logger.SetFormat(logFormat); err != nil {
+		return err
+	}
+	if progressFD > 0 {
+		logger.SetProgressWriter(log.NewFDWriter(progressFD))
+	}
+	if logFile != "" {
+		return openLogFile(logFile)
+	}
+	return nil
+}
+
+// reauth prompts for a new token on auth failures in interactive runs.
+var reauth *reauthenticator
+
+// ocrCtx is cancelled when the run is interrupted.
+var ocrCtx = context.Background()
+
+// runInput is the input path of the run, which output paths are relative to.
+var runInput string
+
+// errInterrupted is returned when the run is cancelled by a signal.
+var errInterrupted = errors.New("interrupted")
+
+// fileResult pairs an input file with its OCR result.
+type fileResult struct {
+	Name   string
+	Result *ocr.DocumentOCRResult
+	// Digest is the input's sha256 with --skip-unchanged, recorded next to
+	// its output.
+	Digest string
+	// Output is the file the result is written to with -o, planned before
+	// OCR (see plannedOutputs).
+	Output string
+}
 
-	// Show current config
-	if showConfig {
-		fmt.Println("Current configuration:\n")
-		if configPath != "" {
-			fmt.Printf("  Config file: %s\n", configPath)
+// ocrArchive extracts a ZIP or TAR archive to a temporary directory and OCRs its files.
+func ocrArchive(client *ocr.Client, archivePath string, opts ocr.OCROptions) ([]fileResult, error) {
+	tmpDir, err := os.MkdirTemp("", "paddleocr-cli-")
+	if err != nil {
+		return nil, i18n.Errorf("main.temp_dir_failed", "Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files, err := archiveFiles(archivePath, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	return ocrFiles(client, files, tmpDir, archivePath, opts)
+}
+
+// archiveFiles extracts the supported files of an archive into tmpDir,
+// subject to --recursive, --max-size and --since, and returns their paths.
+func archiveFiles(archivePath, tmpDir string) ([]string, error) {
+	files, err := fileutil.ExtractArchive(archivePath, tmpDir, fileutil.ExtractOptions{
+		Recursive: recursive,
+		MaxSize:   maxSize * 1024 * 1024,
+		Since:     sinceTime,
+	})
+	if err != nil {
+		return nil, i18n.Errorf("main.extract_failed", "Failed to extract %s: %v", archivePath, err)
+	}
+	if len(files) == 0 {
+		return nil, noFilesError(archivePath)
+	}
+	return files, nil
+}
+
+// ocrDir OCRs the supported files in a directory, descending into
+// subdirectories with --recursive.
+func ocrDir(client *ocr.Client, dir string, opts ocr.OCROptions) ([]fileResult, error) {
+	files, err := dirFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return ocrFiles(client, files, dir, "", opts)
+}
+
+// dirFiles returns the supported files in dir, descending into
+// subdirectories with --recursive and filtered by --since.
+func dirFiles(dir string) ([]string, error) {
+	files, err := fileutil.CollectFiles(dir, recursive)
+	if err == nil && !sinceTime.IsZero() {
+		files, err = fileutil.ModifiedSince(files, sinceTime)
+	}
+	if err != nil {
+		return nil, i18n.Errorf("main.read_failed", "Failed to read %s: %v", dir, err)
+	}
+	if len(files) == 0 {
+		return nil, noFilesError(dir)
+	}
+	return files, nil
+}
+
+// noFilesError reports that a directory or archive had nothing to OCR.
+func noFilesError(path string) error {
+	if !sinceTime.IsZero() {
+		return errors.New(i18n.T("main.no_files_since", "No supported files modified since %s found in %s", sinceTime.Format(time.RFC3339), path))
+	}
+	return errors.New(i18n.T("main.no_files", "No supported files found in %s", path))
+}
+
+// parseSince parses a --since value: a duration before now, such as 24h,
+// or an RFC3339 timestamp or date.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, i18n.Errorf("main.since_negative", "--since duration must not be negative: %s", value)
+		}
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, i18n.Errorf("main.since_invalid", "Invalid --since value %q: use a duration such as 24h or a time such as 2024-05-01T15:04:05Z", value)
+}
+
+// ocrDLQ re-processes the files listed in a --dlq file.
+func ocrDLQ(client *ocr.Client, path string, opts ocr.OCROptions) ([]fileResult, error) {
+	files, err := batch.ReadDLQ(path)
+	if err != nil {
+		return nil, i18n.Errorf("main.read_failed", "Failed to read %s: %v", path, err)
+	}
+	if len(files) == 0 {
+		return nil, i18n.Errorf("main.dlq_empty", "No files listed in %s", path)
+	}
+
+	var results []fileResult
+	for _, file := range files {
+		failed := runFailures.count()
+		var r []fileResult
+		if fileutil.IsArchive(file) {
+			r, err = ocrArchive(client, file, opts)
 		} else {
-			fmt.Println("  Config file: (none found)")
+			r, err = ocrFiles(client, []string{file}, file, "", opts)
 		}
-		fmt.Println()
-		serverDisplay := cfg.PaddleOCR.ServerURL
-		if serverDisplay == "" {
-			serverDisplay = "(not set)"
+		if errors.Is(err, errInterrupted) {
+			return nil, err
 		}
-		fmt.Printf("  Server URL:   %s\n", serverDisplay)
-		tokenDisplay := "(not set)"
-		if len(cfg.PaddleOCR.AccessToken) > 8 {
-			tokenDisplay = "***" + cfg.PaddleOCR.AccessToken[len(cfg.PaddleOCR.AccessToken)-8:]
+		if err != nil && runFailures.count() == failed && runFailures.stopped() == "" {
+			// Not a file failure, such as an archive that cannot be
+			// extracted: recorded as the failure of the listed file.
+			runFailures.add(1)
+			runFailures.record(file, err.Error(), i18n.ErrorID(err))
 		}
-		fmt.Printf("  Access token: %s\n", tokenDisplay)
-		return
+		results = append(results, r...)
 	}
+	if len(results) == 0 && len(skippedFiles) == 0 && conflictCount == 0 {
+		return nil, i18n.Errorf("main.dlq_all_failed", "All files in %s failed", path)
+	}
+	return results, nil
+}
 
-	// Test connection
-	if testConn {
-		if !cfg.IsConfigured() {
-			fmt.Fprintln(os.Stderr, "Error: server_url and access_token must be configured first.")
-			fmt.Fprintln(os.Stderr, "Run: paddleocr-cli configure --server-url URL --token TOKEN")
-			os.Exit(1)
+// ocrDataURI performs OCR on data decoded from a data URI argument, reported
+// under name.
+func ocrDataURI(client *ocr.Client, dataURI *ocr.DataURI, name string, opts ocr.OCROptions) ([]fileResult, error) {
+	output := plannedOutputs([]string{name})[0]
+	if checkOutputs() && skipInput(name, output, "") {
+		return nil, nil
+	}
+	logger.Infof("%s", i18n.T("main.processing_data", "Processing: %s (%d bytes)", dataURI.MediaType, len(dataURI.Data)))
+
+	if autoTimeout {
+		opts.Timeout = sizeTimeout(name, int64(len(dataURI.Data)), opts.Timeout)
+	}
+
+	spin := newSpinner(name)
+	defer spin.close()
+	opts.OnPhase = spin.setPhase
+
+	token := client.AccessToken()
+	result := client.OCRBytesCtx(ocrCtx, dataURI.Data, dataURI.FileType(), name, opts)
+	spin.pause()
+	for reauth.retry(result, token) {
+		token = client.AccessToken()
+		result = client.OCRBytesCtx(ocrCtx, dataURI.Data, dataURI.FileType(), name, opts)
+		spin.pause()
+	}
+	if ocrCtx.Err() != nil {
+		return nil, errInterrupted
+	}
+	printLogID(result)
+	runWarnings.addResult(name, result)
+	if !result.Success {
+		return nil, fmt.Errorf("%s", errorMessage(result))
+	}
+	logger.Infof("%s", i18n.T("main.completed", "OCR completed: %d page(s)", len(result.Pages)))
+	return []fileResult{{Name: name, Result: result, Output: output}}, nil
+}
+
+// ocrFiles performs OCR on each file, up to --concurrency at a time and at
+// most --rate requests per second. Results keep the input order. Failed
+// files are recorded in runFailures, and in the --dlq file when set, and
+// left out of the results; once --fail-fast or --max-failures is reached,
+// in-flight requests are cancelled and the remaining files are left
+// unprocessed. An error is returned only when no file succeeded: the first
+// failure in input order. Result names are relative to baseDir when
+// possible. Files extracted from an archive are recorded in the DLQ under
+// the archive's path.
+func ocrFiles(client *ocr.Client, files []string, baseDir, archive string, opts ocr.OCROptions) ([]fileResult, error) {
+	files, pending := skipInputs(files, resultNames(files, baseDir))
+	if len(files) == 0 {
+		return nil, nil
+	}
+	results := make([]fileResult, len(files))
+	limiter := newRateLimiter(rate)
+	runFailures.add(len(files))
+	if mode := runFailures.stopped(); mode != "" {
+		runFailures.skip(len(files))
+		return nil, i18n.Errorf("main.stopped_by", "Stopped by --%s", mode)
+	}
+
+	// batchCtx is also cancelled when the run is stopped by failures.
+	batchCtx, cancel := context.WithCancel(ocrCtx)
+	defer cancel()
+
+	workers := concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	progress := newBatchProgress(len(files), workers)
+	defer progress.close()
+
+	for i, path := range files {
+		name := pending[i].Name
+
+		sem <- struct{}{}
+		if batchCtx.Err() != nil {
+			<-sem
+			break
 		}
-		fmt.Println("Testing connection to PaddleOCR server...")
-		client := ocr.NewClient(cfg)
-		success, message := client.TestConnection()
-		if success {
-			fmt.Printf("  [OK] %s\n", message)
-		} else {
-			fmt.Printf("  [FAILED] %s\n", message)
-			os.Exit(1)
+		wg.Add(1)
+		go func(i int, path, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			limiter.Wait()
+			if batchCtx.Err() != nil {
+				return
+			}
+			progress.begin(name)
+			progress.logf("%s", i18n.T("main.processing", "Processing: %s", name))
+			started := time.Now()
+
+			// A batch has its progress bar; a single file gets a spinner.
+			fileOpts := opts
+			var spin *spinner
+			if len(files) == 1 {
+				spin = newSpinner(name)
+				defer spin.close()
+				fileOpts.OnPhase = spin.setPhase
+			}
+			if autoTimeout {
+				if info, err := os.Stat(path); err == nil {
+					fileOpts.Timeout = sizeTimeout(name, info.Size(), opts.Timeout)
+				}
+			}
+
+			token := client.AccessToken()
+			result := client.OCRFileCtx(batchCtx, path, fileOpts)
+			spin.pause()
+			for reauth.retry(result, token) {
+				token = client.AccessToken()
+				result = client.OCRFileCtx(batchCtx, path, fileOpts)
+				spin.pause()
+			}
+			if !result.Success && batchCtx.Err() != nil {
+				// Cancelled by a stop or interrupt: left unprocessed.
+				return
+			}
+			runWarnings.addResult(name, result)
+			if result.Success {
+				progress.logf("%s", i18n.T("main.completed", "OCR completed: %d page(s)", len(result.Pages)))
+			} else if runFailures.record(name, errorMessage(result), result.ErrorID) {
+				cancel()
+			}
+			results[i] = pending[i]
+			results[i].Result = result
+			progress.finish(time.Since(started), result.Success)
+		}(i, path, name)
+	}
+	wg.Wait()
+	if ocrCtx.Err() != nil {
+		return nil, errInterrupted
+	}
+
+	succeeded := results[:0:0]
+	var firstErr error
+	for i, r := range results {
+		printLogID(r.Result)
+		switch {
+		case r.Result == nil:
+			runFailures.skip(1)
+		case r.Result.Success:
+			succeeded = append(succeeded, r)
+		default:
+			err := fmt.Errorf("%s", errorMessage(r.Result))
+			if len(files) > 1 {
+				err = fmt.Errorf("%s: %s", r.Name, errorMessage(r.Result))
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			if dlqPath != "" {
+				deadLetter(files[i], archive, r)
+			}
+		}
+	}
+	if len(succeeded) == 0 {
+		if firstErr == nil {
+			firstErr = i18n.Errorf("main.stopped_by", "Stopped by --%s", runFailures.stopped())
 		}
+		return nil, firstErr
+	}
+	return succeeded, nil
+}
+
+// printLogID prints the log id of a result to stdout with
+// --print-log-id-only, in place of the result itself.
+func printLogID(result *ocr.DocumentOCRResult) {
+	if printLogIDOnly && result != nil && result.LogID != "" {
+		fmt.Println(result.LogID)
+	}
+}
+
+// errorMessage returns the error message of a failed result, with a hint at
+// the flag that fixes it when there is one.
+func errorMessage(result *ocr.DocumentOCRResult) string {
+	if errors.Is(result.Err, pdfutil.ErrPasswordRequired) {
+		return result.ErrorMessage + "; provide the password with --pdf-password"
+	}
+	return result.ErrorMessage
+}
+
+// resultNames returns the names results for files are reported under:
+// their paths relative to baseDir when possible.
+func resultNames(files []string, baseDir string) []string {
+	names := make([]string, len(files))
+	for i, path := range files {
+		names[i] = path
+		if rel, err := filepath.Rel(baseDir, path); err == nil && rel != "." {
+			names[i] = filepath.ToSlash(rel)
+		}
+	}
+	return names
+}
+
+// sizeTimeout returns the --auto-timeout for an input of size bytes: the
+// time to move it at --throughput-estimate, but never less than base.
+func sizeTimeout(name string, size int64, base time.Duration) time.Duration {
+	timeout := time.Duration(size) * time.Second / time.Duration(throughputKB*1024)
+	if timeout < base {
+		timeout = base
+	}
+	logger.Debugf("%s: %d bytes, timeout %s", name, size, timeout.Round(time.Second))
+	return timeout
+}
+
+// deadLetter records a failed file in the --dlq file.
+func deadLetter(path, archive string, r fileResult) {
+	item := batch.DLQItem{
+		Path:     path,
+		Error:    r.Result.ErrorMessage,
+		ErrorID:  r.Result.ErrorID,
+		FailedAt: time.Now().UTC(),
+		Attempts: r.Result.Attempts,
+	}
+	if archive != "" {
+		item.Path = archive
+		item.Error = r.Name + ": " + r.Result.ErrorMessage
+	}
+	if abs, err := filepath.Abs(item.Path); err == nil {
+		item.Path = abs
+	}
+
+	if err := batch.AppendDLQ(dlqPath, item); err != nil {
+		runWarnings.Warnf("Failed to write %s: %v", dlqPath, err)
+	}
+	logger.Infof("Failed: %s (added to %s)", r.Name, dlqPath)
+}
+
+// rateLimiter spaces out request starts to at most rate per second.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter returns a limiter for rate requests per second, or nil
+// (no limit) when rate is not positive.
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// Wait blocks until the next request may start.
+func (l *rateLimiter) Wait() {
+	if l == nil {
 		return
 	}
 
-	// Update config
-	if token == "" && serverURL == "" {
-		fmt.Fprintln(os.Stderr, "Usage: paddleocr-cli configure --server-url URL --token TOKEN [-s SCOPE]")
-		fmt.Fprintln(os.Stderr, "\nOptions:")
-		fmt.Fprintln(os.Stderr, "  --server-url URL   Set the server URL (required)")
-		fmt.Fprintln(os.Stderr, "  --token TOKEN      Set the access token (required)")
-		fmt.Fprintln(os.Stderr, "  -s, --scope SCOPE  Installation scope (default: user)")
-		fmt.Fprintln(os.Stderr, "                     user    - ~/.config/paddleocr_cli/")
-		fmt.Fprintln(os.Stderr, "                     project - project root (alongside .claude/)")
-		fmt.Fprintln(os.Stderr, "                     local   - current directory")
-		fmt.Fprintln(os.Stderr, "  --show             Show current configuration")
-		fmt.Fprintln(os.Stderr, "  --test             Test connection")
-		os.Exit(1)
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
 	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
 
-	if token != "" {
-		cfg.PaddleOCR.AccessToken = token
+	time.Sleep(wait)
+}
+
+// warnInsecureConfig warns about loaded config files that hold a token but
+// are readable by others.
+func warnInsecureConfig(cfg *config.Config) {
+	for _, path := range cfg.InsecureFiles() {
+		runWarnings.Warnf("%s contains an access token and is readable by others; run: chmod 600 %s", path, path)
 	}
+}
 
-	if serverURL != "" {
-		cfg.PaddleOCR.ServerURL = serverURL
+// userAgentFor returns the User-Agent to send: --user-agent, then the
+// config's user_agent, then one naming the CLI version and commit.
+func userAgentFor(cfg *config.Config) string {
+	if userAgent != "" {
+		return userAgent
+	}
+	if cfg.PaddleOCR.UserAgent != "" {
+		return cfg.PaddleOCR.UserAgent
 	}
+	return fmt.Sprintf("%s/%s (commit/%s; +https://github.com/Explorer1092/paddleocr_cli)", ocr.DefaultUserAgent, version, commit)
+}
 
-	// Determine save path based on scope
-	savePath, err := config.GetSavePath(scope)
-	if err != nil {
-		if scope == "project" {
-			fmt.Fprintln(os.Stderr, "Error: No project root found (no .claude/ directory in parent paths)")
+// applyDefaults fills flags the user did not set from the config's
+// defaults section and resolves the output format.
+func applyDefaults(cmd *cobra.Command, defaults config.Defaults) error {
+	flags := cmd.Flags()
+	if !flags.Changed("timeout") && defaults.Timeout > 0 {
+		timeout = defaults.Timeout
+	}
+	if !flags.Changed("retries") && defaults.Retries > 0 {
+		retries = defaults.Retries
+	}
+	if !flags.Changed("concurrency") && defaults.Concurrency > 0 {
+		concurrency = defaults.Concurrency
+	}
+	if !flags.Changed("rate") && defaults.Rate > 0 {
+		rate = defaults.Rate
+	}
+	if !flags.Changed("format") && !flags.Changed("json") && defaults.Format != "" {
+		outputFormat = defaults.Format
+	}
+
+	if !slices.Contains(config.OutputFormats, outputFormat) {
+		return i18n.Errorf("main.invalid_format", "Invalid --format %q (valid: %s)", outputFormat, strings.Join(config.OutputFormats, ", "))
+	}
+	if flags.Changed("json") {
+		outputFormat = "json"
+	}
+	jsonOutput = outputFormat == "json"
+	return nil
+}
+
+// applyOptions applies the layered config options (top level, then profile,
+// then preset) to the OCR flags that were not given on the command line.
+func applyOptions(cmd *cobra.Command, options config.Options) {
+	flags := cmd.Flags()
+	if !flags.Changed("orientation") {
+		orientation = options.Orientation
+	}
+	if !flags.Changed("unwarp") {
+		unwarp = options.Unwarp
+	}
+	if !flags.Changed("chart") {
+		chart = options.Chart
+	}
+	if !flags.Changed("insecure") {
+		insecure = options.Insecure
+	}
+}
+
+// formatOutput renders OCR results as markdown or JSON.
+func formatOutput(results []fileResult) (string, error) {
+	if jsonOutput {
+		var outputData interface{}
+		if len(results) == 1 && !runFailures.any() && len(emptyFiles) == 0 && len(skippedFiles) == 0 {
+			data := map[string]interface{}{
+				"success":    true,
+				"pages":      results[0].Result.Pages,
+				"log_id":     results[0].Result.LogID,
+				"request_id": results[0].Result.RequestID,
+			}
+			addPartial(data, results[0].Result)
+			if results[0].Result.Empty {
+				data["empty"] = true
+			}
+			addWarnings(data, results[0].Result.Warnings)
+			outputData = data
 		} else {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			files := make([]map[string]interface{}, 0, len(results))
+			for _, r := range results {
+				file := map[string]interface{}{
+					"file":       r.Name,
+					"pages":      r.Result.Pages,
+					"log_id":     r.Result.LogID,
+					"request_id": r.Result.RequestID,
+				}
+				addPartial(file, r.Result)
+				if r.Result.Empty {
+					file["empty"] = true
+				}
+				addWarnings(file, r.Result.Warnings)
+				files = append(files, file)
+			}
+			data := map[string]interface{}{
+				"success": true,
+				"files":   files,
+			}
+			addWarnings(data, runWarnings.list())
+			runFailures.addTo(data)
+			addEmpty(data)
+			addSkipped(data)
+			outputData = data
 		}
-		os.Exit(1)
+		jsonBytes, err := marshalOutputJSON(outputData)
+		if err != nil {
+			return "", fmt.Errorf("Failed to marshal JSON: %v", err)
+		}
+		return string(jsonBytes), nil
 	}
 
-	// Ensure directory exists
-	if err := os.MkdirAll(filepath.Dir(savePath), 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create directory: %v\n", err)
-		os.Exit(1)
+	var docs []string
+	for _, r := range results {
+		markdown, err := formatMarkdown(r.Result)
+		if err != nil {
+			if len(results) > 1 {
+				return "", fmt.Errorf("%s: %v", r.Name, err)
+			}
+			return "", err
+		}
+		docs = append(docs, markdown)
 	}
 
-	if err := config.Save(cfg, savePath); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to save config: %v\n", err)
-		os.Exit(1)
+	separator := "\n\n---\n\n"
+	if noSeparator {
+		separator = "\n\n"
+	}
+	markdown := strings.Join(docs, separator)
+
+	if toc {
+		if contents := format.TableOfContents(format.ExtractHeadings(outputPages(results))); contents != "" {
+			markdown = contents + "\n\n" + markdown
+		}
+	}
+	return format.Wrap(markdown, wrapWidth), nil
+}
+
+// addPartial adds the expected page count and partial flag to a JSON
+// result when the server reported them.
+func addPartial(data map[string]interface{}, result *ocr.DocumentOCRResult) {
+	if result.ExpectedPages > 0 {
+		data["expected_pages"] = result.ExpectedPages
+	}
+	if result.Partial {
+		data["partial"] = true
+	}
+}
+
+// applyCorrections applies the --corrections rules to each page's
+// markdown.
+func applyCorrections(results []fileResult) {
+	for _, r := range results {
+		replaced := 0
+		for i, page := range r.Result.Pages {
+			var n int
+			r.Result.Pages[i].Markdown, n = format.ApplyCorrections(page.Markdown, corrections)
+			replaced += n
+		}
+		logger.Debugf("%s: %d correction(s) applied", r.Name, replaced)
+	}
+}
+
+// rewriteImageRefs points the image references in every page at baseURL
+// (--image-base-url) instead of the relative path the server used.
+func rewriteImageRefs(results []fileResult, baseURL string) {
+	for _, r := range results {
+		for i, page := range r.Result.Pages {
+			r.Result.Pages[i].Markdown = format.RewriteImageRefs(page.Markdown, page.Images, baseURL)
+		}
+	}
+}
+
+// openSavedOutput opens the -o output in its default application when
+// --open is given. Failing to open it is only a warning, as the output
+// has been saved.
+func openSavedOutput() {
+	if !openOutput {
+		return
+	}
+	if err := platform.OpenFile(outputFile); err != nil {
+		runWarnings.Warnf("cannot open %s: %v", outputFile, err)
+	}
+}
+
+// dropDuplicatePages removes pages that repeat an earlier page of the same
+// result (see format.DuplicatePages), with a warning in the result naming
+// both by their original 1-based page numbers, like other page warnings.
+func dropDuplicatePages(results []fileResult, threshold float64) {
+	for _, r := range results {
+		pages := r.Result.Pages
+		markdown := make([]string, len(pages))
+		for i, page := range pages {
+			markdown[i] = page.Markdown
+		}
+		duplicates := format.DuplicatePages(markdown, threshold)
+		if len(duplicates) == 0 {
+			continue
+		}
+
+		drop := make(map[int]bool, len(duplicates))
+		for _, d := range duplicates {
+			drop[d.Index] = true
+			page, of := pages[d.Index].PageIndex+1, pages[d.Of].PageIndex+1
+			message := fmt.Sprintf("dropped page %d, a duplicate of page %d", page, of)
+			if d.Similarity < 1 {
+				message = fmt.Sprintf("dropped page %d, a near-duplicate of page %d (similarity %.2f)", page, of, d.Similarity)
+			}
+			r.Result.Warnings = append(r.Result.Warnings, message)
+			runWarnings.Warnf("%s: %s", r.Name, message)
+		}
+		kept := pages[:0]
+		for i, page := range pages {
+			if !drop[i] {
+				kept = append(kept, page)
+			}
+		}
+		r.Result.Pages = kept
+	}
+}
+
+// addWarnings adds a "warnings" list to JSON output data when there are any.
+func addWarnings(data map[string]interface{}, warnings []string) {
+	if len(warnings) > 0 {
+		data["warnings"] = warnings
+	}
+}
+
+// outputPages returns the markdown of the pages included in the output, in
+// order, honoring --page.
+func outputPages(results []fileResult) []string {
+	var pages []string
+	for _, r := range results {
+		for i, page := range r.Result.Pages {
+			if pageNum < 0 || i == pageNum {
+				pages = append(pages, page.Markdown)
+			}
+		}
+	}
+	return pages
+}
+
+// formatMarkdown renders a single document as markdown.
+func formatMarkdown(result *ocr.DocumentOCRResult) (string, error) {
+	if pageNum >= 0 {
+		if pageNum < len(result.Pages) {
+			return result.Pages[pageNum].Markdown, nil
+		}
+		return "", i18n.Errorf("main.page_not_found", "Page %d not found (document has %d pages)", pageNum, len(result.Pages))
+	}
+
+	if noSeparator {
+		var parts []string
+		for _, page := range result.Pages {
+			parts = append(parts, page.Markdown)
+		}
+		return strings.Join(parts, "\n\n"), nil
 	}
 
-	fmt.Printf("Configuration saved to: %s\n", savePath)
+	return result.FullMarkdown(), nil
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/metrics"
+)
+
+// metricsExitWait bounds how long exiting waits for a --metrics report still
+// in flight, so a slow endpoint never holds up the user.
+const metricsExitWait = 500 * time.Millisecond
+
+// metricsReport is the anonymized --metrics record of a run, sent in the
+// background once the run's output is written.
+type metricsReport struct {
+	url       string
+	userAgent string
+	record    metrics.Record
+	once      sync.Once
+	done      chan struct{}
+}
+
+// runMetrics is the report of the current run, or nil without --metrics.
+var runMetrics *metricsReport
+
+// newMetricsReport prepares the report of a run for the configured
+// metrics_url. It returns nil, with a warning, when none is configured.
+func newMetricsReport(cfg *config.Config, results []fileResult, success bool, elapsed time.Duration) *metricsReport {
+	if cfg.MetricsURL == "" {
+		runWarnings.Warnf("--metrics given but metrics_url is not configured")
+		return nil
+	}
+
+	m := &metricsReport{
+		url:       cfg.MetricsURL,
+		userAgent: userAgentFor(cfg),
+		record: metrics.Record{
+			Version:    version,
+			DurationMS: elapsed.Milliseconds(),
+			Files:      len(results),
+			Success:    success,
+		},
+		done: make(chan struct{}),
+	}
+	for _, r := range results {
+		m.record.Pages += len(r.Result.Pages)
+	}
+	return m
+}
+
+// send starts sending the report in the background, once. Failures are only
+// reported with --debug.
+func (m *metricsReport) send() {
+	if m == nil {
+		return
+	}
+	m.once.Do(func() {
+		go func() {
+			defer close(m.done)
+			if err := metrics.Send(m.url, m.record, m.userAgent); err != nil {
+				logger.Debugf("Failed to send metrics: %v", err)
+			}
+		}()
+	})
+}
+
+// wait sends the report if that has not started yet and gives it up to
+// metricsExitWait to finish.
+func (m *metricsReport) wait() {
+	if m == nil {
+		return
+	}
+	m.send()
+	select {
+	case <-m.done:
+	case <-time.After(metricsExitWait):
+	}
+}
+
+// exit ends the run with code after a bounded wait for the --metrics
+// report.
+func exit(code int) {
+	runMetrics.wait()
+	os.Exit(code)
+}
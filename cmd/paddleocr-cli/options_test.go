@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+)
+
+func TestApplyOptionsFlagsWin(t *testing.T) {
+	defer func(o, u, c, i bool) { orientation, unwarp, chart, insecure = o, u, c, i }(orientation, unwarp, chart, insecure)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().BoolVar(&orientation, "orientation", false, "")
+	cmd.Flags().BoolVar(&unwarp, "unwarp", false, "")
+	cmd.Flags().BoolVar(&chart, "chart", false, "")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "")
+	if err := cmd.ParseFlags([]string{"--orientation=false", "--chart"}); err != nil {
+		t.Fatal(err)
+	}
+
+	applyOptions(cmd, config.Options{Orientation: true, Unwarp: true, Chart: false, Insecure: true})
+
+	if orientation {
+		t.Error("orientation = true, want false from --orientation=false")
+	}
+	if !unwarp {
+		t.Error("unwarp = false, want true from the config")
+	}
+	if !chart {
+		t.Error("chart = false, want true from --chart")
+	}
+	if !insecure {
+		t.Error("insecure = false, want true from the config")
+	}
+}
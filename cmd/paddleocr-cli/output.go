@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/fileutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/format"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+)
+
+// isOutputDir reports whether -o names a directory: an existing one, or a
+// path ending in a separator.
+func isOutputDir(path string) bool {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(os.PathSeparator)) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// marshalOutputJSON encodes v as JSON indented by --json-indent spaces, or
+// minified with --json-compact or an indent of 0.
+func marshalOutputJSON(v interface{}) ([]byte, error) {
+	if jsonCompact || jsonIndent <= 0 {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", strings.Repeat(" ", jsonIndent))
+}
+
+// writeOutputDir writes one output file per input into the -o directory,
+// at the path planned for it before OCR (see plannedOutputs).
+func writeOutputDir(results []fileResult) error {
+	existing := 0
+	for _, r := range results {
+		output, err := formatOutput([]fileResult{r})
+		if err != nil {
+			return fmt.Errorf("%s: %v", r.Name, err)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(r.Output), 0755); err != nil {
+			return fmt.Errorf("Failed to create directory: %v", err)
+		}
+		written, err := writeOutputFile(r.Output, []byte(output))
+		if errors.Is(err, fileutil.ErrExists) {
+			existing++
+		} else if err != nil {
+			return err
+		}
+		if written {
+			writeOutputMeta(r.Output, r)
+		}
+	}
+	return existingError(existing + conflictCount)
+}
+
+// writeOutputFile writes one output file, handling an existing one as
+// --on-exists says, and reports whether it was written. In error mode the
+// existing file is reported and an error wrapping fileutil.ErrExists is
+// returned, so batch writers can carry on with the other files.
+func writeOutputFile(path string, data []byte) (bool, error) {
+	if err := checkFreeSpace(path); err != nil {
+		return false, err
+	}
+	err := fileutil.SafeWrite(path, data, collisionMode(), outputFileMode)
+	switch {
+	case errors.Is(err, fileutil.ErrSkipped):
+		logger.Infof("%s", i18n.T("output.skipped", "Skipped existing output: %s", path))
+		return false, nil
+	case errors.Is(err, fileutil.ErrExists):
+		logger.Errorf("%s", i18n.T("output.exists", "Output file already exists: %s", path))
+		return false, err
+	case err != nil:
+		return false, errors.New(i18n.T("output.write_failed", "Failed to write output: %v", err))
+	}
+	logger.Infof("%s", i18n.T("output.saved", "Output saved to: %s", path))
+	return true, nil
+}
+
+// existingError returns the error for a batch that left n existing output
+// files alone in --on-exists error mode, whether found before OCR or when
+// writing, or nil.
+func existingError(n int) error {
+	if n == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d output file(s) already exist; use --on-exists overwrite, skip or backup to write them", n)
+}
+
+// collisionMode returns how existing output files are handled, from
+// --on-exists or its shorthands --overwrite, --skip, --skip-existing and
+// --backup. It is applied before OCR (see skipReason) and again when
+// writing, for files that appeared in between and --split-on-heading.
+func collisionMode() fileutil.CollisionMode {
+	switch {
+	case onExists != "":
+		mode, _ := fileutil.ParseCollisionMode(onExists)
+		return mode
+	case skipOutput, skipExisting && !forceRun:
+		return fileutil.CollisionSkip
+	case backupOutput:
+		return fileutil.CollisionBackup
+	default:
+		return fileutil.CollisionOverwrite
+	}
+}
+
+// outputPaths maps each result to its output file under dir (see
+// outputNamer).
+func outputPaths(results []fileResult, inputPath, dir string) []string {
+	var namer outputNamer
+	paths := make([]string, 0, len(results))
+	for _, r := range results {
+		paths = append(paths, namer.path(r.Name, inputPath, dir))
+	}
+	return paths
+}
+
+// outputNamer assigns inputs their output files in a directory. Files are
+// flattened into the directory unless --preserve-structure is set, in which
+// case their path relative to the input root is recreated; names that
+// would collide get a numeric suffix, in the order inputs are named. An
+// input named again gets the path it was given before.
+type outputNamer struct {
+	used   map[string]bool
+	byName map[string]string
+}
+
+// path returns the output file under dir of the input name, where
+// inputPath is the run's input.
+func (n *outputNamer) path(name, inputPath, dir string) string {
+	if path, ok := n.byName[name]; ok {
+		return path
+	}
+	if n.used == nil {
+		n.used = make(map[string]bool)
+		n.byName = make(map[string]string)
+	}
+
+	ext := ".md"
+	if jsonOutput {
+		ext = ".json"
+	}
+	rel := filepath.FromSlash(name)
+	if name == inputPath || !preserveStructure {
+		rel = filepath.Base(rel)
+	}
+	stem := filepath.Join(dir, strings.TrimSuffix(rel, filepath.Ext(rel)))
+
+	path := stem + ext
+	for i := 1; n.used[path]; i++ {
+		path = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	n.used[path] = true
+	n.byName[name] = path
+	return path
+}
+
+// writeSections splits the combined markdown of all results at
+// --split-on-heading headings and writes one file per section into dir,
+// named after the heading, along with the images the section references.
+func writeSections(results []fileResult, dir string) error {
+	images := make(map[string]string)
+	for _, r := range results {
+		for _, page := range r.Result.Pages {
+			for name, value := range page.Images {
+				images[name] = value
+			}
+		}
+	}
+
+	sections := format.SplitSections(strings.Join(outputPages(results), "\n\n"), splitHeading)
+	if len(sections) == 0 {
+		return fmt.Errorf("No content to write")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("Failed to create directory: %v", err)
+	}
+
+	written := make(map[string]bool)
+	existing := 0
+	for _, section := range sections {
+		path := filepath.Join(dir, section.Slug+".md")
+		markdown := format.Wrap(section.Markdown, wrapWidth)
+		ok, err := writeOutputFile(path, []byte(markdown+"\n"))
+		if errors.Is(err, fileutil.ErrExists) {
+			existing++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		for _, name := range format.ImageRefs(section.Markdown, images) {
+			if written[name] {
+				continue
+			}
+			written[name] = true
+			if err := writeImage(dir, name, images[name]); errors.Is(err, errLowDiskSpace) {
+				return err
+			} else if err != nil {
+				runWarnings.Warnf("Failed to save image %s: %v", name, err)
+			}
+		}
+	}
+	return existingError(existing)
+}
+
+// writeImage saves an image from an OCR result under dir at the relative
+// path the markdown references it by.
+func writeImage(dir, name, value string) error {
+	rel := filepath.FromSlash(name)
+	if !filepath.IsLocal(rel) {
+		return fmt.Errorf("refusing to write outside the output directory")
+	}
+
+	data, err := fetchImage(value)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, rel)
+	if err := checkFreeSpace(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fileutil.AtomicWrite(path, data, outputFileMode)
+}
+
+// fetchImage returns the bytes of an image given as a URL, a data URI or
+// base64 data, as the API returns them.
+func fetchImage(value string) ([]byte, error) {
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		client := &http.Client{Timeout: time.Duration(timeout) * time.Second}
+		resp, err := client.Get(value)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+
+	if strings.HasPrefix(value, "data:") {
+		if _, data, ok := strings.Cut(value, ","); ok {
+			value = data
+		}
+	}
+	return base64.StdEncoding.DecodeString(value)
+}
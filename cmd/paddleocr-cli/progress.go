@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/log"
+)
+
+const (
+	// progressInterval is how often the plain-text progress line is logged.
+	progressInterval = 10 * time.Second
+	// etaWindow is the number of recent file durations the ETA averages.
+	etaWindow = 20
+)
+
+// batchProgress reports progress through a run over several files. On a
+// terminal it draws a bar on the last line of stderr, redrawn in place,
+// with log messages printed above it; otherwise, or with --no-progress, it
+// logs a summary line every progressInterval. A nil *batchProgress does
+// nothing, so single-file runs need no checks.
+type batchProgress struct {
+	mu        sync.Mutex
+	total     int
+	workers   int
+	done      int
+	failed    int
+	current   string
+	start     time.Time
+	durations []time.Duration
+
+	// bar is set when drawing the bar. stop ends the ticker that redraws
+	// the bar or logs the summary line.
+	bar  bool
+	stop chan struct{}
+}
+
+// newBatchProgress returns the progress reporter for total files processed
+// by workers in parallel, or nil when there is a single file.
+func newBatchProgress(total, workers int) *batchProgress {
+	if total < 2 {
+		return nil
+	}
+	p := &batchProgress{total: total, workers: workers, start: time.Now(), stop: make(chan struct{})}
+
+	p.bar = !noProgress && stderrIsTerminal() && progressFD == 0 &&
+		logFormat == log.FormatText && logger.Level() == log.LevelInfo
+	interval := progressInterval
+	if p.bar {
+		logger.SetOutput(p)
+		interval = time.Second
+	}
+	go p.tick(interval)
+	return p
+}
+
+// tick redraws the bar, or logs the summary line, every interval until
+// p.stop is closed, so progress shows even while every worker is busy
+// with a long file.
+func (p *batchProgress) tick(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.mu.Lock()
+			if p.bar {
+				p.draw()
+				p.mu.Unlock()
+				continue
+			}
+			// finish logs the last line itself.
+			var line string
+			if p.done < p.total {
+				line = p.summary()
+			}
+			p.mu.Unlock()
+			// Logged without p.mu, as in finish.
+			if line != "" {
+				logger.Infof("%s", line)
+			}
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// logf logs a per-file progress message. While the bar is drawn, which
+// already names the current file, it is only logged at debug level.
+func (p *batchProgress) logf(format string, args ...interface{}) {
+	if p != nil && p.bar {
+		logger.Debugf(format, args...)
+		return
+	}
+	logger.Infof(format, args...)
+}
+
+// begin records that processing of name has started.
+func (p *batchProgress) begin(name string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = name
+	p.draw()
+}
+
+// finish records that a file took d and whether it succeeded.
+func (p *batchProgress) finish(d time.Duration, ok bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.done++
+	if !ok {
+		p.failed++
+	}
+	p.durations = append(p.durations, d)
+	if len(p.durations) > etaWindow {
+		p.durations = p.durations[1:]
+	}
+	p.draw()
+
+	var line string
+	if !p.bar && p.done == p.total {
+		line = p.summary()
+	}
+	p.mu.Unlock()
+
+	// Logged without p.mu: the logger holds its own lock while calling
+	// Write, which takes p.mu.
+	if line != "" {
+		logger.Infof("%s", line)
+	}
+}
+
+// close stops the ticker, removes the bar and gives stderr back to the
+// logger.
+func (p *batchProgress) close() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	if !p.bar {
+		return
+	}
+	logger.SetOutput(os.Stderr)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bar = false
+	fmt.Fprint(os.Stderr, "\r\x1b[K")
+}
+
+// Write prints log output above the bar: it clears the bar's line, writes
+// p and draws the bar again below it.
+func (p *batchProgress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.bar {
+		fmt.Fprint(os.Stderr, "\r\x1b[K")
+	}
+	n, err := os.Stderr.Write(b)
+	p.draw()
+	return n, err
+}
+
+// summary returns the plain-text progress line.
+func (p *batchProgress) summary() string {
+	line := fmt.Sprintf("%d/%d done", p.done, p.total)
+	if p.failed > 0 {
+		line += fmt.Sprintf(", %d failed", p.failed)
+	}
+	if eta, ok := p.eta(); ok && p.done < p.total {
+		line += ", ETA " + formatETA(eta)
+	}
+	return line
+}
+
+// eta estimates the time left from the average of the recent file
+// durations, spread over the workers.
+func (p *batchProgress) eta() (time.Duration, bool) {
+	if len(p.durations) == 0 {
+		return 0, false
+	}
+	var sum time.Duration
+	for _, d := range p.durations {
+		sum += d
+	}
+	remaining := p.total - p.done
+	parallel := min(p.workers, remaining)
+	if parallel < 1 {
+		parallel = 1
+	}
+	return sum / time.Duration(len(p.durations)) * time.Duration(remaining) / time.Duration(parallel), true
+}
+
+// draw redraws the bar. The caller holds p.mu.
+func (p *batchProgress) draw() {
+	if !p.bar {
+		return
+	}
+	width := 80
+	if w, _, err := term.GetSize(int(os.Stderr.Fd())); err == nil && w > 0 {
+		width = w
+	}
+
+	const barWidth = 20
+	filled := barWidth * p.done / p.total
+	status := fmt.Sprintf("[%s%s] %d/%d", strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), p.done, p.total)
+	if p.failed > 0 {
+		status += fmt.Sprintf(" (%d failed)", p.failed)
+	}
+	status += ", elapsed " + formatETA(time.Since(p.start))
+	if eta, ok := p.eta(); ok {
+		status += ", ETA " + formatETA(eta)
+	}
+
+	status = fitStatus(status, p.current, width-1)
+	if colorEnabled(os.Stderr) {
+		// Colored after fitting, which measures the visible text.
+		status = strings.Replace(status, "["+strings.Repeat("=", filled), "["+log.StyleGreen+strings.Repeat("=", filled)+log.StyleReset, 1)
+		if failed := fmt.Sprintf("(%d failed)", p.failed); p.failed > 0 {
+			status = strings.Replace(status, failed, log.StyleRed+failed+log.StyleReset, 1)
+		}
+	}
+	fmt.Fprint(os.Stderr, "\r"+status+"\x1b[K")
+}
+
+// fitStatus appends name to status and cuts the result to width terminal
+// cells. The file name gets whatever room status leaves, keeping its end;
+// widths are measured in cells, so wide CJK characters count double and
+// multi-byte characters are never split.
+func fitStatus(status, name string, width int) string {
+	if room := width - runewidth.StringWidth(status) - 2; room > 3 && name != "" {
+		if w := runewidth.StringWidth(name); w > room {
+			name = runewidth.TruncateLeft(name, w-room+3, "...")
+		}
+		status += "  " + name
+	}
+	return runewidth.Truncate(status, width, "")
+}
+
+// formatETA formats a duration rounded to the second, or to the minute
+// beyond an hour.
+func formatETA(d time.Duration) string {
+	if d >= time.Hour {
+		return d.Round(time.Minute).String()
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+func TestFitStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		file   string
+		width  int
+		want   string
+	}{
+		{"fits", "1/2", "a.png", 20, "1/2  a.png"},
+		{"no name", "1/2", "", 20, "1/2"},
+		{"name keeps its end", "1/2", "scans/2024/page.png", 17, "1/2  .../page.png"},
+		{"CJK name counts cells", "1/2", "扫描件/第一页.png", 16, "1/2  ...一页.png"},
+		{"wide rune is not split", "1/2", "扫描件/第一页.png", 17, "1/2  ... 一页.png"},
+		{"no room for a name", "[====] 1/2", "a.png", 12, "[====] 1/2"},
+		{"status cut to width", "[====] 1/2, elapsed 3s", "a.png", 10, "[====] 1/2"},
+		{"status with wide runes", "进度 1/2", "", 5, "进度 "},
+	}
+	for _, tt := range tests {
+		got := fitStatus(tt.status, tt.file, tt.width)
+		if got != tt.want {
+			t.Errorf("%s: fitStatus() = %q, want %q", tt.name, got, tt.want)
+		}
+		if w := runewidth.StringWidth(got); w > tt.width {
+			t.Errorf("%s: %q is %d cells wide, want at most %d", tt.name, got, w, tt.width)
+		}
+	}
+}
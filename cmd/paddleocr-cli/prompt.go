@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+)
+
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// stderrIsTerminal reports whether stderr is an interactive terminal.
+func stderrIsTerminal() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// readToken prints prompt to stderr and reads a token from the terminal
+// with echo disabled. When stdin is not a terminal, one line is read from it
+// instead so tokens can be piped in.
+func readToken(prompt string) (string, error) {
+	if !stdinIsTerminal() {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read token from stdin: %v", err)
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	input, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %v", err)
+	}
+	return strings.TrimSpace(string(input)), nil
+}
+
+// promptPassphrase asks for the passphrase of an encrypted access token. It
+// is only used on a terminal, so piped input is never mistaken for it.
+func promptPassphrase() (string, error) {
+	if !stdinIsTerminal() {
+		return "", fmt.Errorf("access_token_encrypted is set but $%s is not", config.PassphraseEnvVar)
+	}
+	return readToken("Passphrase for access token: ")
+}
+
+// newPassphrase returns the passphrase to encrypt a token with: the value
+// of $PADDLEOCR_PASSPHRASE, or one entered twice at a terminal prompt.
+func newPassphrase() (string, error) {
+	if env := os.Getenv(config.PassphraseEnvVar); env != "" {
+		return env, nil
+	}
+	if !stdinIsTerminal() {
+		return "", fmt.Errorf("Set $%s or run in a terminal to enter a passphrase", config.PassphraseEnvVar)
+	}
+	passphrase, err := readToken("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("No passphrase entered")
+	}
+	confirm, err := readToken("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != passphrase {
+		return "", fmt.Errorf("Passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// readLine prints prompt to stderr and reads one line from stdin.
+func readLine(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+)
+
+// reauthenticator prompts for a replacement token when the server rejects
+// the current one, saves it and lets the caller retry. It only prompts when
+// stdin is a terminal; concurrent workers share a single prompt.
+type reauthenticator struct {
+	mu     sync.Mutex
+	client *ocr.Client
+	cfg    *config.Config
+}
+
+func newReauthenticator(client *ocr.Client, cfg *config.Config) *reauthenticator {
+	if !stdinIsTerminal() {
+		return nil
+	}
+	return &reauthenticator{client: client, cfg: cfg}
+}
+
+// retry reports whether a request that failed with an auth error using
+// failedToken should be retried. It prompts for and saves a new token unless
+// another worker already replaced it.
+func (r *reauthenticator) retry(result *ocr.DocumentOCRResult, failedToken string) bool {
+	if r == nil || !errors.Is(result.Err, ocr.ErrUnauthorized) {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.client.AccessToken() != failedToken {
+		return true
+	}
+
+	token, err := readToken("Access token was rejected. Enter a new token (empty to abort): ")
+	if err != nil || token == "" {
+		return false
+	}
+
+	r.client.SetAccessToken(token)
+	if key := r.cfg.TokenKey(); strings.HasSuffix(key, ".access_token_file") {
+		// The token file may be shared or managed elsewhere, so it is not
+		// rewritten.
+		logger.Infof("Using the new token for this run only; update the file named by %s to keep it", key)
+		return true
+	}
+	if path, err := r.save(token); err != nil {
+		runWarnings.Warnf("Failed to save token: %v", err)
+	} else {
+		logger.Infof("Token saved to: %s", path)
+	}
+	return true
+}
+
+// save writes the token to the file and key that supplied the rejected one,
+// encrypted again if that key was access_token_encrypted.
+func (r *reauthenticator) save(token string) (string, error) {
+	key := r.cfg.TokenKey()
+	value := token
+	if strings.HasSuffix(key, ".access_token_encrypted") {
+		encrypted, err := r.cfg.EncryptToken(token)
+		if err != nil {
+			return "", err
+		}
+		value = encrypted
+	}
+
+	path := r.cfg.Origin("paddleocr.access_token")
+	if path == "" {
+		userPath, err := config.UserConfigPath()
+		if err != nil {
+			return "", err
+		}
+		path = userPath
+	}
+
+	doc, err := config.LoadDocument(path)
+	if err != nil {
+		return "", err
+	}
+	if err := doc.Set(key, value); err != nil {
+		return "", err
+	}
+	return path, doc.Save()
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/update"
+)
+
+var (
+	updateCheck  bool
+	updateForce  bool
+	updateProxy  string
+	updateCACert string
+	updateDryRun bool
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update paddleocr-cli to the latest release",
+	Long: `Download the latest release from GitHub for this OS and architecture,
+verify it against the release's checksums.txt and replace the running
+executable.
+
+With --check nothing is downloaded: the command reports whether a newer
+release exists and exits 0 if there is one, 1 otherwise. With --dry-run
+the command shows the release archive it would download and the executable
+it would replace, without downloading or replacing anything.
+
+The executable's directory must be writable by the current user, as the
+new binary is written next to it and renamed over it.
+
+Binaries installed under a package manager's directory (such as /usr/bin or
+Homebrew's Cellar) are left alone unless --force is given; update them with
+the package manager instead.`,
+	Args: cobra.NoArgs,
+	Run:  runSelfUpdate,
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&updateCheck, "check", false, "Only report whether an update is available (exit 0 if so, 1 if not)")
+	selfUpdateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "Show what would be downloaded and replaced, without doing it")
+	selfUpdateCmd.Flags().BoolVar(&updateForce, "force", false, "Update even a development build or a package-manager install, or reinstall the same version")
+	selfUpdateCmd.Flags().StringVar(&updateProxy, "proxy", "", "HTTP(S) proxy URL for the download (default: $HTTPS_PROXY)")
+	selfUpdateCmd.Flags().StringVar(&updateCACert, "ca-cert", "", "PEM file with extra CA certificates to trust, e.g. a corporate TLS proxy's")
+
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) {
+	client, err := updateHTTPClient()
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	release, err := update.Latest(client, update.LatestReleaseURL)
+	if err != nil {
+		logger.Errorf("Failed to check for updates: %v", err)
+		os.Exit(1)
+	}
+
+	newer, err := update.Newer(release.Version(), version)
+	if err != nil && !updateForce {
+		logger.Errorf("Cannot compare this build (%s) with %s: %v", version, release.TagName, err)
+		fmt.Fprintln(os.Stderr, "Use --force to install the latest release anyway.")
+		os.Exit(1)
+	}
+
+	if updateCheck {
+		if newer {
+			fmt.Printf("Update available: %s -> %s\n", version, release.Version())
+			if release.HTMLURL != "" {
+				fmt.Println(release.HTMLURL)
+			}
+			return
+		}
+		fmt.Printf("Up to date: %s\n", version)
+		os.Exit(1)
+	}
+
+	if !newer && !updateForce {
+		fmt.Printf("Already up to date: %s\n", version)
+		return
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		logger.Errorf("Cannot locate the running executable: %v", err)
+		os.Exit(1)
+	}
+	if update.IsManaged(exe) && !updateForce {
+		logger.Errorf("%s looks like it was installed by a package manager; update it with that instead, or use --force", exe)
+		os.Exit(1)
+	}
+
+	if err := update.Writable(exe); err != nil {
+		logger.Errorf("Cannot replace %s: %v", exe, err)
+		fmt.Fprintln(os.Stderr, "Run self-update as a user who can write to its directory, or reinstall it.")
+		os.Exit(1)
+	}
+
+	archiveName := update.ArchiveName(runtime.GOOS, runtime.GOARCH)
+	if updateDryRun {
+		asset, err := release.Asset(archiveName)
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Would download %s\n", asset.URL)
+		fmt.Printf("Would verify it against %s and replace %s: %s -> %s\n", update.ChecksumsAsset, exe, version, release.Version())
+		return
+	}
+	binary, err := downloadRelease(client, release, archiveName)
+	if err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	if err := update.Replace(exe, binary, runtime.GOOS == "windows"); err != nil {
+		logger.Errorf("Failed to replace %s: %v", exe, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Updated %s: %s -> %s\n", exe, version, release.Version())
+}
+
+// downloadRelease downloads the platform archive and checksums of release,
+// verifies the archive and returns the executable inside it.
+func downloadRelease(client *http.Client, release *update.Release, archiveName string) ([]byte, error) {
+	archiveAsset, err := release.Asset(archiveName)
+	if err != nil {
+		return nil, err
+	}
+	checksumsAsset, err := release.Asset(update.ChecksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Downloading %s", archiveAsset.URL)
+	archive, err := update.Download(client, archiveAsset)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to download %s: %v", archiveName, err)
+	}
+	checksums, err := update.Download(client, checksumsAsset)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to download %s: %v", update.ChecksumsAsset, err)
+	}
+	if err := update.VerifyChecksum(checksums, archiveName, archive); err != nil {
+		return nil, err
+	}
+
+	binary, err := update.ExtractBinary(archive, archiveName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to extract %s: %v", archiveName, err)
+	}
+	return binary, nil
+}
+
+// updateHTTPClient returns the client for release downloads, honoring
+// --proxy and --ca-cert.
+func updateHTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if updateProxy != "" {
+		proxyURL, err := url.Parse(updateProxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("Invalid --proxy URL: %s", updateProxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if updateCACert != "" {
+		pem, err := os.ReadFile(updateCACert)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read --ca-cert: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificates found in %s", updateCACert)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &http.Client{Transport: transport, Timeout: 5 * time.Minute}, nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/fileutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+)
+
+// metaSuffix is appended to an output path to name its sidecar file, which
+// records the input the output was written from for --skip-unchanged.
+const metaSuffix = ".meta.json"
+
+// outputMeta is the content of an output's sidecar file.
+type outputMeta struct {
+	Source string `json:"source"`
+	SHA256 string `json:"sha256"`
+}
+
+// skippedFile is an input left out of a run because its output exists, as
+// listed in the JSON output.
+type skippedFile struct {
+	File   string `json:"file"`
+	Output string `json:"output"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// skippedFiles are the inputs left out of this run because their output
+// exists, by --on-exists skip (or --skip, --skip-existing) or
+// --skip-unchanged.
+var skippedFiles []skippedFile
+
+// runOutputs assigns the inputs of this run their files in the -o
+// directory. It is shared by every batch of the run, so inputs of a later
+// batch, such as the next entry of a --dlq file, do not take the paths of
+// earlier ones.
+var runOutputs outputNamer
+
+// conflictCount is the number of inputs left out of this run because their
+// output exists in --on-exists error mode.
+var conflictCount int
+
+// skipping reports whether --skip-existing or --skip-unchanged is given and
+// --force is not.
+func skipping() bool {
+	return (skipExisting || skipUnchanged) && !forceRun
+}
+
+// skipFlag returns the skip flag in use, for messages.
+func skipFlag() string {
+	if skipUnchanged {
+		return "--skip-unchanged"
+	}
+	return "--skip-existing"
+}
+
+// checkOutputs reports whether existing outputs are checked before OCR:
+// whenever outputs go to -o, except with --split-on-heading, whose files
+// are only known once the text is.
+func checkOutputs() bool {
+	return outputFile != "" && splitHeading == 0
+}
+
+// skipReason decides, before OCR, what happens to an input whose output
+// would be written to output when that file exists. It returns why the
+// input is skipped, or "" to process it; an error wrapping
+// fileutil.ErrExists means the output exists in --on-exists error mode.
+// digest is the input's sha256, compared with --skip-unchanged to the one
+// recorded in the output's sidecar file.
+func skipReason(output, digest string) (string, error) {
+	if _, err := os.Stat(output); err != nil {
+		return "", nil
+	}
+	switch collisionMode() {
+	case fileutil.CollisionSkip:
+		return "output exists", nil
+	case fileutil.CollisionError:
+		return "", fmt.Errorf("%s: %w", output, fileutil.ErrExists)
+	}
+	if !skipUnchanged || forceRun {
+		return "", nil
+	}
+	meta, err := readOutputMeta(output)
+	if err != nil || digest == "" || meta.SHA256 != digest {
+		return "", nil
+	}
+	return "input unchanged", nil
+}
+
+// skipInputs drops the inputs whose existing output means they are not
+// processed, logging each, and returns the others with the result each
+// starts from: its name, planned output and, with --skip-unchanged, sha256
+// digest. Outputs are planned over all the inputs, skipped ones included,
+// so every input keeps the path it had when it was written. Inputs that
+// cannot be read are kept, so processing reports the error.
+func skipInputs(files, names []string) ([]string, []fileResult) {
+	outputs := plannedOutputs(names)
+	pending := make([]fileResult, len(files))
+	for i := range files {
+		pending[i] = fileResult{Name: names[i], Output: outputs[i]}
+		if skipUnchanged {
+			pending[i].Digest, _ = fileDigest(files[i])
+		}
+	}
+	if !checkOutputs() {
+		return files, pending
+	}
+
+	var keptFiles []string
+	var kept []fileResult
+	for i, r := range pending {
+		if skipInput(r.Name, r.Output, r.Digest) {
+			continue
+		}
+		keptFiles = append(keptFiles, files[i])
+		kept = append(kept, r)
+	}
+	return keptFiles, kept
+}
+
+// skipInput applies skipReason to the input name, logging and counting it
+// when it is left out, and reports whether it is.
+func skipInput(name, output, digest string) bool {
+	reason, err := skipReason(output, digest)
+	switch {
+	case err != nil:
+		logger.Errorf("%s", i18n.T("output.exists", "Output file already exists: %s", output))
+		conflictCount++
+		return true
+	case reason != "":
+		logger.Infof("Skipped %s: %s", name, reason)
+		skippedFiles = append(skippedFiles, skippedFile{File: name, Output: output, Status: "skipped", Reason: reason})
+		return true
+	}
+	return false
+}
+
+// plannedOutputs returns the output path of each named input: its file in
+// the -o directory (see runOutputs), the -o file itself, or "" without -o
+// or with --split-on-heading.
+func plannedOutputs(names []string) []string {
+	paths := make([]string, len(names))
+	switch {
+	case outputFile == "" || splitHeading > 0:
+	case isOutputDir(outputFile):
+		for i, name := range names {
+			paths[i] = runOutputs.path(name, runInput, outputFile)
+		}
+	default:
+		for i := range paths {
+			paths[i] = outputFile
+		}
+	}
+	return paths
+}
+
+// addSkipped lists the skipped inputs in a batch's JSON output.
+func addSkipped(data map[string]interface{}) {
+	if len(skippedFiles) > 0 {
+		data["skipped"] = skippedFiles
+	}
+}
+
+// fileDigest returns the hex sha256 of a file's content.
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readOutputMeta reads the sidecar file of output.
+func readOutputMeta(output string) (*outputMeta, error) {
+	data, err := os.ReadFile(output + metaSuffix)
+	if err != nil {
+		return nil, err
+	}
+	var meta outputMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// writeOutputMeta records the input of a written output in its sidecar
+// file, when the input's digest is known (--skip-unchanged). Failing to is
+// only a warning: the next run processes the input again.
+func writeOutputMeta(output string, r fileResult) {
+	if r.Digest == "" {
+		return
+	}
+	data, err := json.MarshalIndent(outputMeta{Source: r.Name, SHA256: r.Digest}, "", "  ")
+	if err == nil {
+		err = fileutil.AtomicWrite(output+metaSuffix, append(data, '\n'), outputFileMode)
+	}
+	if err != nil {
+		runWarnings.Warnf("Failed to record the input of %s: %v", output, err)
+	}
+}
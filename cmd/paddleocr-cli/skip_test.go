@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSkipInputsBeforeOCR(t *testing.T) {
+	defer func(out, mode string, skip, backup, existing, unchanged, force bool) {
+		outputFile, onExists, skipOutput, backupOutput, skipExisting, skipUnchanged, forceRun = out, mode, skip, backup, existing, unchanged, force
+		skippedFiles, conflictCount, runOutputs = nil, 0, outputNamer{}
+	}(outputFile, onExists, skipOutput, backupOutput, skipExisting, skipUnchanged, forceRun)
+
+	in, out := t.TempDir(), t.TempDir()+string(os.PathSeparator)
+	var files []string
+	for _, name := range []string{"a.png", "b.png"} {
+		path := filepath.Join(in, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	// Only a.png has been processed before.
+	if err := os.WriteFile(filepath.Join(out, "a.md"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		set       func()
+		kept      []string
+		skipped   int
+		conflicts int
+	}{
+		{"overwrite by default", func() {}, []string{"a.png", "b.png"}, 0, 0},
+		{"--on-exists overwrite", func() { onExists = "overwrite" }, []string{"a.png", "b.png"}, 0, 0},
+		{"--on-exists backup", func() { onExists = "backup" }, []string{"a.png", "b.png"}, 0, 0},
+		{"--backup", func() { backupOutput = true }, []string{"a.png", "b.png"}, 0, 0},
+		{"--on-exists skip", func() { onExists = "skip" }, []string{"b.png"}, 1, 0},
+		{"--skip", func() { skipOutput = true }, []string{"b.png"}, 1, 0},
+		{"--skip-existing", func() { skipExisting = true }, []string{"b.png"}, 1, 0},
+		{"--skip-existing --force", func() { skipExisting, forceRun = true, true }, []string{"a.png", "b.png"}, 0, 0},
+		{"--skip-unchanged without a record", func() { skipUnchanged = true }, []string{"a.png", "b.png"}, 0, 0},
+		{"--on-exists error", func() { onExists = "error" }, []string{"b.png"}, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile, onExists, skipOutput, backupOutput = out, "", false, false
+			skipExisting, skipUnchanged, forceRun = false, false, false
+			skippedFiles, conflictCount = nil, 0
+			tt.set()
+
+			kept, pending := skipInputs(files, resultNames(files, in))
+			var names []string
+			for _, r := range pending {
+				names = append(names, r.Name)
+			}
+			if !slices.Equal(names, tt.kept) || len(kept) != len(tt.kept) {
+				t.Errorf("kept %q, want %q", names, tt.kept)
+			}
+			if len(skippedFiles) != tt.skipped || conflictCount != tt.conflicts {
+				t.Errorf("skipped %d, conflicts %d; want %d, %d", len(skippedFiles), conflictCount, tt.skipped, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestSkipInputsKeepsPlannedOutputs(t *testing.T) {
+	defer func(out, mode, input string) {
+		outputFile, onExists, runInput = out, mode, input
+		skippedFiles, runOutputs = nil, outputNamer{}
+	}(outputFile, onExists, runInput)
+
+	// scan.png was processed alone before, into scan.md; scan.pdf has
+	// been added since and sorts first.
+	in, out := t.TempDir(), t.TempDir()+string(os.PathSeparator)
+	var files []string
+	for _, name := range []string{"scan.pdf", "scan.png"} {
+		path := filepath.Join(in, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, path)
+	}
+	if err := os.WriteFile(filepath.Join(out, "scan.md"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode    string
+		kept    []string
+		outputs []string
+		skipped []skippedFile
+	}{
+		{"overwrite", []string{"scan.pdf", "scan.png"}, []string{"scan.md", "scan-1.md"}, nil},
+		{"skip", []string{"scan.png"}, []string{"scan-1.md"},
+			[]skippedFile{{File: "scan.pdf", Output: out + "scan.md", Status: "skipped", Reason: "output exists"}}},
+	}
+	for _, tt := range tests {
+		outputFile, onExists, runInput = out, tt.mode, in
+		skippedFiles, runOutputs = nil, outputNamer{}
+
+		_, pending := skipInputs(files, resultNames(files, in))
+		var names, outputs []string
+		for _, r := range pending {
+			names = append(names, r.Name)
+			outputs = append(outputs, filepath.Base(r.Output))
+		}
+		if !slices.Equal(names, tt.kept) || !slices.Equal(outputs, tt.outputs) {
+			t.Errorf("%s: kept %q writing %q, want %q writing %q", tt.mode, names, outputs, tt.kept, tt.outputs)
+		}
+		if !slices.Equal(skippedFiles, tt.skipped) {
+			t.Errorf("%s: skipped %+v, want %+v", tt.mode, skippedFiles, tt.skipped)
+		}
+	}
+
+	// A later batch of the same run, such as the next --dlq entry, does
+	// not take a path given out already.
+	if got := plannedOutputs([]string{filepath.Join(in, "other", "scan.png")}); filepath.Base(got[0]) != "scan-2.md" {
+		t.Errorf("later batch output = %q, want scan-2.md", got[0])
+	}
+}
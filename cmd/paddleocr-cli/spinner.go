@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/log"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+)
+
+// spinnerFrames are drawn in turn, one per spinnerInterval.
+const (
+	spinnerFrames   = `|/-\`
+	spinnerInterval = 100 * time.Millisecond
+)
+
+// spinner shows the phase of a single-file request on the last line of
+// stderr, so a long server call does not look frozen. Log messages are
+// printed above it. It only draws while a request is in flight: pause
+// clears it, for example before a token prompt. A nil *spinner does
+// nothing, so callers need no checks.
+type spinner struct {
+	mu     sync.Mutex
+	name   string
+	phase  ocr.Phase
+	size   int64
+	since  time.Time
+	frame  int
+	active bool
+	stop   chan struct{}
+}
+
+// newSpinner returns a spinner for the request for name, or nil when
+// stderr is not a terminal, with --quiet or --no-progress, or when log
+// output is not plain text at info level.
+func newSpinner(name string) *spinner {
+	if noProgress || quiet || !stderrIsTerminal() || progressFD != 0 ||
+		logFormat != log.FormatText || logger.Level() != log.LevelInfo {
+		return nil
+	}
+	s := &spinner{name: name, stop: make(chan struct{})}
+	logger.SetOutput(s)
+	go func() {
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.mu.Lock()
+				s.frame++
+				s.draw()
+				s.mu.Unlock()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// setPhase records the request's current phase; it is an
+// ocr.OCROptions.OnPhase callback.
+func (s *spinner) setPhase(phase ocr.Phase, size int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active || phase != s.phase {
+		s.since = time.Now()
+	}
+	s.phase, s.size, s.active = phase, size, true
+	s.draw()
+}
+
+// pause clears the spinner until the next phase is reported.
+func (s *spinner) pause() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active {
+		s.active = false
+		fmt.Fprint(os.Stderr, "\r\x1b[K")
+	}
+}
+
+// close clears the spinner and gives stderr back to the logger. It must be
+// called before any final output or error is printed.
+func (s *spinner) close() {
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	logger.SetOutput(os.Stderr)
+	s.pause()
+}
+
+// Write prints log output above the spinner.
+func (s *spinner) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active {
+		fmt.Fprint(os.Stderr, "\r\x1b[K")
+	}
+	n, err := os.Stderr.Write(b)
+	s.draw()
+	return n, err
+}
+
+// draw redraws the spinner line. The caller holds s.mu.
+func (s *spinner) draw() {
+	if !s.active {
+		return
+	}
+	var status string
+	switch s.phase {
+	case ocr.PhaseUploading:
+		status = "uploading " + formatSize(s.size)
+	case ocr.PhaseWaiting:
+		status = "waiting for server " + formatElapsed(time.Since(s.since))
+	case ocr.PhaseParsing:
+		status = "parsing response"
+	default:
+		status = s.phase.String()
+	}
+	line := fmt.Sprintf("%c %s: %s", spinnerFrames[s.frame%len(spinnerFrames)], s.name, status)
+	if w, _, err := term.GetSize(int(os.Stderr.Fd())); err == nil && len(line) > w-1 && w > 1 {
+		line = line[:w-1]
+	}
+	fmt.Fprint(os.Stderr, "\r"+line+"\x1b[K")
+}
+
+// formatSize formats a byte count in B, KB or MB.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// formatElapsed formats a duration as 12s or 1m04s.
+func formatElapsed(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/fileutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/update"
+)
+
+const (
+	// NoUpdateCheckEnvVar disables the new-version notice when set.
+	NoUpdateCheckEnvVar = "PADDLEOCR_NO_UPDATE_CHECK"
+
+	// updateCheckInterval is how often the latest release is looked up.
+	updateCheckInterval = 24 * time.Hour
+	// updateCheckBudget bounds how long the check may take, counted from
+	// the start of the command.
+	updateCheckBudget = 2 * time.Second
+)
+
+// updateNotice holds a lookup of the latest release, started before the
+// command runs and reported after it succeeds. result receives the latest
+// version, or "" when the lookup failed.
+var updateNotice struct {
+	deadline time.Time
+	result   chan string
+}
+
+// versionCache is the last lookup of the latest release, shared by the
+// new-version notice and version --check. Latest is empty when the lookup
+// failed or is still running.
+type versionCache struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest,omitempty"`
+}
+
+// noNoticeCommands never check for updates.
+var noNoticeCommands = map[string]bool{
+	"self-update":                   true,
+	"version":                       true,
+	"completion":                    true,
+	"docs":                          true,
+	"help":                          true,
+	cobra.ShellCompRequestCmd:       true,
+	cobra.ShellCompNoDescRequestCmd: true,
+}
+
+// startUpdateCheck starts looking up the latest release in the background
+// at most once per updateCheckInterval, for interactive runs that have not
+// opted out. Within the interval the cached version is reported instead.
+func startUpdateCheck(cmd *cobra.Command, args []string) {
+	for c := cmd; c != nil; c = c.Parent() {
+		if noNoticeCommands[c.Name()] {
+			return
+		}
+	}
+	if os.Getenv(NoUpdateCheckEnvVar) != "" || quiet || !stderrIsTerminal() || updateCheckDisabled() {
+		return
+	}
+
+	updateNotice.deadline = time.Now().Add(updateCheckBudget)
+	updateNotice.result = make(chan string, 1)
+	if cache, ok := readVersionCache(); ok {
+		updateNotice.result <- cache.Latest
+		return
+	}
+	// Recorded before looking up, so a failing lookup is not retried
+	// until the interval has passed.
+	if writeVersionCache(versionCache{CheckedAt: time.Now()}) != nil {
+		updateNotice.result = nil
+		return
+	}
+
+	go func() {
+		client := &http.Client{Timeout: updateCheckBudget}
+		release, err := update.Latest(client, update.LatestReleaseURL)
+		if err != nil {
+			updateNotice.result <- ""
+			return
+		}
+		writeVersionCache(versionCache{CheckedAt: time.Now(), Latest: release.Version()})
+		updateNotice.result <- release.Version()
+	}()
+}
+
+// finishUpdateCheck prints a notice when the background lookup found a
+// newer release in time. Failures are silent.
+func finishUpdateCheck(cmd *cobra.Command, args []string) {
+	if updateNotice.result == nil {
+		return
+	}
+
+	var latest string
+	select {
+	case latest = <-updateNotice.result:
+	case <-time.After(time.Until(updateNotice.deadline)):
+	}
+	if latest == "" {
+		return
+	}
+	if newer, err := update.Newer(latest, version); err == nil && newer {
+		logger.Infof("A newer version (v%s) is available; run 'paddleocr-cli self-update' to install it", latest)
+	}
+}
+
+// updateCheckDisabled reports whether a config file sets
+// disable_update_check. The files are read as plain documents, so no
+// token is decrypted or prompted for.
+func updateCheckDisabled() bool {
+	paths := []string{configFile}
+	if configFile == "" {
+		paths = config.FindConfigs()
+	}
+	for _, path := range paths {
+		doc, err := config.LoadDocument(path)
+		if err != nil {
+			continue
+		}
+		if value, err := doc.Get("disable_update_check"); err == nil && value == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// versionCachePath returns the file holding the versionCache, in the
+// user's cache directory (~/.cache/paddleocr_cli/version_check.json on
+// Linux).
+func versionCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, config.AppName, "version_check.json"), nil
+}
+
+// readVersionCache returns the cached lookup when it was made within
+// updateCheckInterval.
+func readVersionCache() (versionCache, bool) {
+	var cache versionCache
+	path, err := versionCachePath()
+	if err != nil {
+		return cache, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &cache) != nil {
+		return cache, false
+	}
+	return cache, time.Since(cache.CheckedAt) < updateCheckInterval
+}
+
+// writeVersionCache replaces the cached lookup.
+func writeVersionCache(cache versionCache) error {
+	path, err := versionCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return fileutil.AtomicWrite(path, append(data, '\n'), 0644)
+}
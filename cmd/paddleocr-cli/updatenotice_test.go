@@ -0,0 +1,37 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestVersionCache(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	if _, ok := readVersionCache(); ok {
+		t.Fatal("readVersionCache() found a cache before any was written")
+	}
+	if err := writeVersionCache(versionCache{CheckedAt: time.Now(), Latest: "1.2.3"}); err != nil {
+		t.Fatalf("writeVersionCache: %v", err)
+	}
+	if cache, ok := readVersionCache(); !ok || cache.Latest != "1.2.3" {
+		t.Errorf("readVersionCache() = %+v, %v; want 1.2.3, fresh", cache, ok)
+	}
+	if path, _ := versionCachePath(); filepath.Base(path) != "version_check.json" {
+		t.Errorf("versionCachePath() = %q, want a version_check.json file", path)
+	}
+
+	// A lookup older than the interval is stale; one that failed has no
+	// version but still counts as a check.
+	writeVersionCache(versionCache{CheckedAt: time.Now().Add(-updateCheckInterval - time.Minute), Latest: "1.2.3"})
+	if _, ok := readVersionCache(); ok {
+		t.Error("readVersionCache() returned a stale lookup as fresh")
+	}
+	writeVersionCache(versionCache{CheckedAt: time.Now()})
+	if cache, ok := readVersionCache(); !ok || cache.Latest != "" {
+		t.Errorf("readVersionCache() = %+v, %v; want a fresh failed check", cache, ok)
+	}
+}
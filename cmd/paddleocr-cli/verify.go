@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+)
+
+var (
+	verifyJSON   bool
+	verifyStrict bool
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [PATH...]",
+	Short: "Check config files offline, for CI and pre-commit hooks",
+	Long: `Check config files without contacting a server or decrypting credentials:
+YAML syntax, unknown keys, server URL format, placeholder or missing tokens,
+missing token files, out-of-range defaults and a default_profile that no
+profile in the file defines. A plaintext token in a file inside a git
+repository is reported as a warning.
+
+Checks each PATH, or every discovered config file when none is given.
+Exits non-zero if any error is found, or with --strict any warning.
+
+Unlike 'configure --test', nothing here needs the network.`,
+	Example: `  paddleocr-cli verify .paddleocr_cli.yaml
+  paddleocr-cli verify --strict --json`,
+	Run: runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyJSON, "json", false, "Output results as JSON")
+	verifyCmd.Flags().BoolVar(&verifyStrict, "strict", false, "Fail on warnings as well as errors")
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) {
+	paths := args
+	if len(paths) == 0 {
+		paths = config.FindConfigs()
+	}
+	if len(paths) == 0 {
+		logger.Errorf("No config files found")
+		os.Exit(1)
+	}
+
+	if !printValidation(paths, verifyJSON, verifyStrict) {
+		os.Exit(1)
+	}
+}
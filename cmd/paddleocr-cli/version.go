@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	rtdebug "runtime/debug"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+	"github.com/Explorer1092/paddleocr_cli/internal/update"
+)
+
+var (
+	versionJSON  bool
+	checkServer  bool
+	versionCheck bool
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show version and build information",
+	Long: `Show the version, commit, build date, Go version and platform.
+
+With --json the output is a single JSON object with the keys version,
+commit, date, go_version, os and arch, plus server with --check-server
+and update with --check.
+With --check-server the configured servers' health endpoints are queried
+and the command exits with status 1 if any is unreachable.
+With --check the latest release is looked up on GitHub, at most once a
+day (the result is cached in ~/.cache/paddleocr_cli/version_check.json,
+shared with the new-version notice), and the command exits with status 1
+if the lookup fails. Set GITHUB_TOKEN to authenticate the request.`,
+	Args: cobra.NoArgs,
+	Run:  runVersion,
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Output as JSON")
+	versionCmd.Flags().BoolVar(&checkServer, "check-server", false, "Also check that the configured servers are reachable and healthy")
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "Also report whether a newer release is available")
+	versionCmd.Flags().StringVar(&configFile, "config", "", "Path to config file")
+
+	rootCmd.AddCommand(versionCmd)
+
+	resolveBuildInfo()
+	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date)
+}
+
+// resolveBuildInfo fills in the version, commit and build date from the
+// module and VCS information Go embeds in the binary when they were not set
+// with -ldflags, as in 'go install' builds.
+func resolveBuildInfo() {
+	info, ok := rtdebug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	if version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch {
+		case setting.Key == "vcs.revision" && commit == "none":
+			commit = setting.Value
+		case setting.Key == "vcs.time" && date == "unknown":
+			date = setting.Value
+		}
+	}
+}
+
+// versionInfo is the --json output of the version command.
+type versionInfo struct {
+	Version   string        `json:"version"`
+	Commit    string        `json:"commit"`
+	Date      string        `json:"date"`
+	GoVersion string        `json:"go_version"`
+	OS        string        `json:"os"`
+	Arch      string        `json:"arch"`
+	Server    *serverStatus `json:"server,omitempty"`
+	Update    *updateStatus `json:"update,omitempty"`
+}
+
+// serverStatus is the result of --check-server.
+type serverStatus struct {
+	URLs      []string `json:"urls"`
+	Reachable bool     `json:"reachable"`
+	Message   string   `json:"message"`
+}
+
+func runVersion(cmd *cobra.Command, args []string) {
+	info := versionInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	if checkServer {
+		info.Server = serverHealth()
+	}
+	if versionCheck {
+		info.Update = checkForUpdate()
+	}
+
+	if versionJSON {
+		jsonBytes, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			logger.Errorf("%v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonBytes))
+	} else {
+		fmt.Printf("paddleocr-cli %s\n", info.Version)
+		fmt.Printf("  Commit:   %s\n", info.Commit)
+		fmt.Printf("  Built:    %s\n", info.Date)
+		fmt.Printf("  Go:       %s\n", info.GoVersion)
+		fmt.Printf("  Platform: %s/%s\n", info.OS, info.Arch)
+		if info.Server != nil {
+			status := "reachable"
+			if !info.Server.Reachable {
+				status = "unreachable"
+			}
+			fmt.Printf("  Server:   %s\n", status)
+			fmt.Printf("            %s\n", info.Server.Message)
+		}
+		if info.Update != nil {
+			fmt.Printf("  Update:   %s\n", info.Update.Message)
+		}
+	}
+
+	if info.Server != nil && !info.Server.Reachable {
+		os.Exit(1)
+	}
+	if info.Update != nil && info.Update.Latest == "" {
+		os.Exit(1)
+	}
+}
+
+// serverHealth checks the servers of the loaded config.
+func serverHealth() *serverStatus {
+	cfg, err := config.Load(configFile)
+	if err == nil {
+		err = cfg.ApplyProfile(profileName)
+	}
+	if err == nil {
+		err = cfg.ApplyPreset(presetName)
+	}
+	if err != nil {
+		return &serverStatus{URLs: []string{}, Message: i18n.T("config.load_failed", "Failed to load config: %v", err)}
+	}
+	if !cfg.IsConfigured() {
+		return &serverStatus{URLs: append([]string{}, cfg.PaddleOCR.Servers()...), Message: i18n.T("configure.need_credentials", "server_url and access_token must be configured first.")}
+	}
+
+	client := ocr.NewClientWithOptions(cfg, ocr.ClientOptions{UserAgent: userAgentFor(cfg), Insecure: cfg.Options.Insecure})
+	reachable, message := client.TestConnection()
+	return &serverStatus{URLs: client.ServerURLs(), Reachable: reachable, Message: message}
+}
+
+// updateStatus is the result of --check. Latest is empty when the lookup
+// failed.
+type updateStatus struct {
+	Latest    string `json:"latest,omitempty"`
+	Available bool   `json:"available"`
+	Message   string `json:"message"`
+}
+
+// checkForUpdate compares this build with the latest release.
+func checkForUpdate() *updateStatus {
+	latest, err := latestVersion()
+	if err != nil {
+		return &updateStatus{Message: fmt.Sprintf("Failed to check for updates: %v", err)}
+	}
+	status := &updateStatus{Latest: latest, Message: "Up to date"}
+	newer, err := update.Newer(latest, version)
+	switch {
+	case err != nil:
+		status.Message = fmt.Sprintf("Cannot compare this build (%s) with v%s: %v", version, latest, err)
+	case newer:
+		status.Available = true
+		status.Message = "Update available: v" + latest
+	}
+	return status
+}
+
+// latestVersion returns the version of the latest release, from the
+// version check cache when it was looked up within updateCheckInterval.
+// Failing to update the cache is only a warning.
+func latestVersion() (string, error) {
+	if cache, ok := readVersionCache(); ok && cache.Latest != "" {
+		return cache.Latest, nil
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	release, err := update.Latest(client, update.LatestReleaseURL)
+	if err != nil {
+		return "", err
+	}
+	if err := writeVersionCache(versionCache{CheckedAt: time.Now(), Latest: release.Version()}); err != nil {
+		logger.Warnf("Failed to cache the latest version: %v", err)
+	}
+	return release.Version(), nil
+}
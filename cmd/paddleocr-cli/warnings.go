@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr"
+)
+
+// runWarnings collects the non-fatal issues of the current run.
+var runWarnings warningCollector
+
+// warningCollector records warnings as they are logged so they can be
+// repeated as one list when the run ends, where they are not lost in the
+// progress output of a long batch.
+type warningCollector struct {
+	mu    sync.Mutex
+	items []string
+}
+
+// Warnf logs a warning and records it.
+func (w *warningCollector) Warnf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	logger.Warnf("%s", message)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.items = append(w.items, message)
+}
+
+// addResult logs and records the warnings of the OCR result for name,
+// whether or not the file succeeded.
+func (w *warningCollector) addResult(name string, result *ocr.DocumentOCRResult) {
+	for _, warning := range result.Warnings {
+		w.Warnf("%s: %s", name, warning)
+	}
+}
+
+// list returns the warnings recorded so far.
+func (w *warningCollector) list() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.items...)
+}
+
+// summarize logs the recorded warnings as a single list.
+func (w *warningCollector) summarize() {
+	items := w.list()
+	if len(items) == 0 {
+		return
+	}
+	noun := "warnings"
+	if len(items) == 1 {
+		noun = "warning"
+	}
+	logger.Warnf("%d %s:\n  - %s", len(items), noun, strings.Join(items, "\n  - "))
+}
@@ -0,0 +1,78 @@
+// Package batch holds helpers for multi-file OCR runs.
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DLQItem is one permanently failed input in a dead letter queue file.
+// Error is in the user's locale; ErrorID, when known, is its stable
+// message ID.
+type DLQItem struct {
+	Path     string    `json:"path"`
+	Error    string    `json:"error"`
+	ErrorID  string    `json:"error_id,omitempty"`
+	FailedAt time.Time `json:"failed_at"`
+	Attempts int       `json:"attempts"`
+}
+
+// AppendDLQ appends item as a JSON line to the file at path, creating it if
+// needed, and syncs the file so the entry survives a crash.
+func AppendDLQ(path string, item DLQItem) error {
+	line, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadDLQ returns the paths listed in a dead letter queue file, in order and
+// without duplicates.
+func ReadDLQ(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var paths []string
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var item DLQItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+		if item.Path != "" && !seen[item.Path] {
+			seen[item.Path] = true
+			paths = append(paths, item.Path)
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// IsDLQ reports whether path names a dead letter queue file.
+func IsDLQ(path string) bool {
+	return filepath.Ext(path) == ".jsonl"
+}
@@ -1,33 +1,204 @@
 // Package config handles configuration management for PaddleOCR CLI.
 //
-// Config file search order:
+// Config file search order (when no explicit file is given, all found files
+// are merged and earlier entries take precedence):
 //  1. Current directory (./.paddleocr_cli.yaml)
-//  2. Project root (alongside .claude/ directory)
-//  3. User config directory (~/.config/paddleocr_cli/config.yaml)
+//  2. Project root (the nearest parent with .claude/, .git, .paddleocr-root
+//     or another of ProjectMarkers)
+//  3. User config directory ($XDG_CONFIG_HOME/paddleocr_cli/config.yaml,
+//     %APPDATA%\paddleocr_cli\config.yaml on Windows, otherwise
+//     ~/.config/paddleocr_cli/config.yaml), or config.yaml in the directory
+//     set by SetUserConfigDir or $PADDLEOCR_CONFIG_DIR
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/fileutil"
 )
 
 const (
 	ConfigFilename = ".paddleocr_cli.yaml"
 	UserConfigDir  = ".config/paddleocr_cli"
 	UserConfigFile = "config.yaml"
+	AppName        = "paddleocr_cli"
 )
 
 // PaddleOCRConfig holds the PaddleOCR API configuration.
 type PaddleOCRConfig struct {
-	ServerURL   string `yaml:"server_url"`
-	AccessToken string `yaml:"access_token"`
+	ServerURL string `yaml:"server_url"`
+	// ServerURLs lists replicas that requests are spread across. When set,
+	// it is used instead of ServerURL.
+	ServerURLs  []string `yaml:"server_urls,omitempty,flow"`
+	AccessToken string   `yaml:"access_token" secret:"true"`
+	// AccessTokens lists tokens that requests rotate through. When set, it
+	// is used instead of an AccessToken from the same file; a file of higher
+	// precedence that sets either replaces both.
+	AccessTokens []string `yaml:"access_tokens,omitempty,flow" secret:"true"`
+	// AccessTokenEncrypted holds the access token encrypted with a
+	// passphrase (see package crypt), decrypted at load time when
+	// access_token is not set.
+	AccessTokenEncrypted string `yaml:"access_token_encrypted,omitempty" secret:"true"`
+	// AccessTokenFile names a file holding the access token, read at load
+	// time when access_token is not set.
+	AccessTokenFile string `yaml:"access_token_file,omitempty"`
+	// TOTPSecret is a base32 RFC 6238 secret for gateways that require a
+	// second factor: when set, the current TOTP code is appended to the
+	// access token in the Authorization header. It may be stored encrypted
+	// like access_token_encrypted.
+	TOTPSecret string `yaml:"totp_secret,omitempty" secret:"true"`
+	// BasePath is a path prefix inserted between the server URL and the API
+	// endpoints, for servers behind a reverse proxy (e.g. "/api/v1/paddleocr").
+	BasePath   string `yaml:"base_path,omitempty"`
+	APIVersion string `yaml:"api_version,omitempty"`
+	UserAgent  string `yaml:"user_agent,omitempty"`
+	// RequestFields renames top-level request fields for servers that
+	// expect different names, mapping the standard name of the API version
+	// (e.g. "file" or "fileType") to the server's (e.g. "image").
+	RequestFields map[string]string `yaml:"request_fields,omitempty"`
+}
+
+// Servers returns the configured server URLs: ServerURLs if set, otherwise
+// ServerURL.
+func (p *PaddleOCRConfig) Servers() []string {
+	if len(p.ServerURLs) > 0 {
+		return p.ServerURLs
+	}
+	if p.ServerURL != "" {
+		return []string{p.ServerURL}
+	}
+	return nil
+}
+
+// Tokens returns the configured access tokens: AccessTokens if set,
+// otherwise AccessToken.
+func (p *PaddleOCRConfig) Tokens() []string {
+	if len(p.AccessTokens) > 0 {
+		return p.AccessTokens
+	}
+	if p.AccessToken != "" {
+		return []string{p.AccessToken}
+	}
+	return nil
+}
+
+// APIVersions lists the accepted api_version values. An empty value means
+// the version is detected from the server.
+var APIVersions = []string{"v1", "v2"}
+
+// Validate checks the API settings.
+func (p *PaddleOCRConfig) Validate() error {
+	if p.APIVersion != "" && !slices.Contains(APIVersions, p.APIVersion) {
+		return fmt.Errorf("paddleocr.api_version must be one of %s, got %q", strings.Join(APIVersions, ", "), p.APIVersion)
+	}
+	if p.BasePath != "" && !strings.HasPrefix(p.BasePath, "/") {
+		return fmt.Errorf("paddleocr.base_path must start with \"/\", got %q", p.BasePath)
+	}
+	names := make([]string, 0, len(p.RequestFields))
+	for name := range p.RequestFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	renamed := make(map[string]string, len(names))
+	for _, name := range names {
+		target := p.RequestFields[name]
+		if target == "" {
+			return fmt.Errorf("paddleocr.request_fields.%s must not be empty", name)
+		}
+		if other, ok := renamed[target]; ok {
+			return fmt.Errorf("paddleocr.request_fields maps both %q and %q to %q", other, name, target)
+		}
+		renamed[target] = name
+	}
+	return nil
+}
+
+// Defaults holds run-level defaults, applied when the matching command-line
+// flag is not given.
+type Defaults struct {
+	Timeout     int     `yaml:"timeout,omitempty"`
+	Retries     int     `yaml:"retries,omitempty"`
+	Concurrency int     `yaml:"concurrency,omitempty"`
+	Rate        float64 `yaml:"rate,omitempty"`
+	Format      string  `yaml:"format,omitempty"`
+}
+
+// OutputFormats lists the accepted output formats.
+var OutputFormats = []string{"markdown", "json"}
+
+// Validate checks that the defaults are within their allowed ranges.
+func (d *Defaults) Validate() error {
+	if d.Timeout < 0 {
+		return fmt.Errorf("defaults.timeout must be >= 0, got %d", d.Timeout)
+	}
+	if d.Retries < 0 || d.Retries > 100 {
+		return fmt.Errorf("defaults.retries must be between 0 and 100, got %d", d.Retries)
+	}
+	if d.Concurrency < 0 || d.Concurrency > 64 {
+		return fmt.Errorf("defaults.concurrency must be between 0 and 64, got %d", d.Concurrency)
+	}
+	if d.Rate < 0 {
+		return fmt.Errorf("defaults.rate must be >= 0, got %g", d.Rate)
+	}
+	if d.Format != "" && !slices.Contains(OutputFormats, d.Format) {
+		return fmt.Errorf("defaults.format must be one of %s, got %q", strings.Join(OutputFormats, ", "), d.Format)
+	}
+	return nil
+}
+
+// Options holds default OCR options, applied when the matching flag is not
+// given. They can be set at the top level, per profile and per preset,
+// layered in that order.
+type Options struct {
+	Orientation bool `yaml:"orientation,omitempty"`
+	Unwarp      bool `yaml:"unwarp,omitempty"`
+	Chart       bool `yaml:"chart,omitempty"`
+	// Insecure skips TLS certificate verification, for servers with
+	// self-signed certificates such as staging deployments.
+	Insecure bool `yaml:"insecure,omitempty"`
 }
 
 // Config is the main configuration structure.
 type Config struct {
-	PaddleOCR PaddleOCRConfig `yaml:"paddleocr"`
+	PaddleOCR      PaddleOCRConfig    `yaml:"paddleocr"`
+	Defaults       Defaults           `yaml:"defaults,omitempty"`
+	Options        Options            `yaml:"options,omitempty"`
+	DefaultProfile string             `yaml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+	// Presets are named sets of options selected with --preset.
+	Presets map[string]Options `yaml:"presets,omitempty"`
+	// MetricsURL receives anonymized run timings when --metrics is given.
+	MetricsURL string `yaml:"metrics_url,omitempty"`
+	// DisableUpdateCheck turns off the daily new-version notice.
+	DisableUpdateCheck bool `yaml:"disable_update_check,omitempty"`
+
+	// origins maps dotted keys (e.g. "paddleocr.server_url") to the file
+	// that supplied their effective value.
+	origins map[string]string
+	// sources lists the loaded files, lowest precedence first.
+	sources []string
+	// present holds the dotted keys explicitly set in the loaded files,
+	// so that false and 0 override lower layers.
+	present map[string]bool
+	// profile is the name of the applied profile, if any.
+	profile string
+	// preset is the name of the applied preset, if any.
+	preset string
+	// overlays maps the keys set by the applied profile or preset to
+	// "profile" or "preset".
+	overlays map[string]string
+	// insecure lists loaded files holding a token that others can read.
+	insecure []string
+	// token records the key that supplied the access token.
+	token tokenSource
 }
 
 // New creates a new empty Config.
@@ -35,9 +206,37 @@ func New() *Config {
 	return &Config{}
 }
 
+// Origin returns the file that supplied the value of a dotted key, or ""
+// if the value was not loaded from a file.
+func (c *Config) Origin(key string) string {
+	return c.origins[key]
+}
+
+// OverlaidBy reports whether the value of a dotted key was set by the
+// applied profile ("profile") or preset ("preset"), or neither ("").
+func (c *Config) OverlaidBy(key string) string {
+	return c.overlays[key]
+}
+
+// overlay wraps an origin function to record the keys set by an overlay.
+func (c *Config) overlay(kind string, origin func(key string) string) func(key string) string {
+	if c.overlays == nil {
+		c.overlays = make(map[string]string)
+	}
+	return func(key string) string {
+		c.overlays[key] = kind
+		return origin(key)
+	}
+}
+
+// Sources returns the config files that were loaded, lowest precedence first.
+func (c *Config) Sources() []string {
+	return c.sources
+}
+
 // IsConfigured checks if the configuration has required fields set.
 func (c *Config) IsConfigured() bool {
-	return c.PaddleOCR.ServerURL != "" && c.PaddleOCR.AccessToken != ""
+	return len(c.PaddleOCR.Servers()) > 0 && len(c.PaddleOCR.Tokens()) > 0
 }
 
 // GetScriptDir returns the directory of the current executable.
@@ -49,20 +248,185 @@ func GetScriptDir() (string, error) {
 	return filepath.Dir(exe), nil
 }
 
-// GetProjectRoot finds the project root by looking for .claude/ directory.
+// Platform directory lookups, replaceable so path resolution can be
+// exercised independently of the host OS.
+var (
+	userHomeDir   = os.UserHomeDir
+	userConfigDir = os.UserConfigDir
+	goos          = runtime.GOOS
+)
+
+// ConfigDirEnvVar replaces the user config directory when SetUserConfigDir
+// has not been given one.
+const ConfigDirEnvVar = "PADDLEOCR_CONFIG_DIR"
+
+// userConfigDirOverride is the directory set by SetUserConfigDir.
+var userConfigDirOverride string
+
+// SetUserConfigDir makes dir the user config directory for all config
+// operations, in place of the platform location and $PADDLEOCR_CONFIG_DIR,
+// so several isolated setups can share a machine. An empty dir restores the
+// default.
+func SetUserConfigDir(dir string) {
+	userConfigDirOverride = dir
+}
+
+// configDirOverride returns the user config directory set by
+// SetUserConfigDir or $PADDLEOCR_CONFIG_DIR, made absolute, or "".
+func configDirOverride() string {
+	dir := userConfigDirOverride
+	if dir == "" {
+		dir = os.Getenv(ConfigDirEnvVar)
+	}
+	if dir == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return dir
+}
+
+// LegacyUserConfigPath returns the historical ~/.config/paddleocr_cli/config.yaml path.
+func LegacyUserConfigPath() (string, error) {
+	home, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, UserConfigDir, UserConfigFile), nil
+}
+
+// PlatformUserConfigPath returns the platform-native user config file path:
+// $XDG_CONFIG_HOME when set, %APPDATA% on Windows, ~/.config elsewhere. An
+// overridden user config directory (see SetUserConfigDir) takes precedence.
+func PlatformUserConfigPath() (string, error) {
+	if dir := configDirOverride(); dir != "" {
+		return filepath.Join(dir, UserConfigFile), nil
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" && filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, AppName, UserConfigFile), nil
+	}
+
+	if goos == "windows" {
+		dir, err := userConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, AppName, UserConfigFile), nil
+	}
+
+	return LegacyUserConfigPath()
+}
+
+// UserConfigPath returns the user-scope config file path.
+//
+// The platform-native location is used unless only a legacy config file
+// exists, in which case the legacy file keeps being used.
+func UserConfigPath() (string, error) {
+	path, err := PlatformUserConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	if legacy, ok := legacyOnly(path); ok {
+		return legacy, nil
+	}
+	return path, nil
+}
+
+// LegacyConfigHint returns a migration hint when a legacy user config exists
+// but the platform-native one does not, or "" otherwise.
+func LegacyConfigHint() string {
+	path, err := PlatformUserConfigPath()
+	if err != nil {
+		return ""
+	}
+
+	legacy, ok := legacyOnly(path)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("Legacy config found at %s; consider moving it to %s", legacy, path)
+}
+
+// legacyOnly returns the legacy path if it exists and differs from path,
+// which does not exist. An overridden user config directory never falls
+// back to the legacy file.
+func legacyOnly(path string) (string, bool) {
+	if configDirOverride() != "" {
+		return "", false
+	}
+	legacy, err := LegacyUserConfigPath()
+	if err != nil || legacy == path {
+		return "", false
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", false
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return "", false
+	}
+	return legacy, true
+}
+
+// ProjectMarkers lists the entries that mark a project root, in priority
+// order when a directory holds more than one. .claude must be a directory;
+// .git may be a directory or, in worktrees and submodules, a file.
+var ProjectMarkers = []string{".claude", ".git", ".paddleocr-root"}
+
+// AddProjectMarkers adds names, such as go.mod or pyproject.toml, to
+// ProjectMarkers after the built-in ones. Names already listed are
+// skipped.
+func AddProjectMarkers(names ...string) {
+	for _, name := range names {
+		if name != "" && !slices.Contains(ProjectMarkers, name) {
+			ProjectMarkers = append(ProjectMarkers, name)
+		}
+	}
+}
+
+// DescribeProjectMarkers lists ProjectMarkers for messages, as in
+// ".claude/, .git or .paddleocr-root".
+func DescribeProjectMarkers() string {
+	names := make([]string, len(ProjectMarkers))
+	for i, name := range ProjectMarkers {
+		names[i] = name
+		if name == ".claude" {
+			names[i] += "/"
+		}
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// GetProjectRoot finds the project root (see FindProjectRoot).
 func GetProjectRoot() string {
+	root, _ := FindProjectRoot()
+	return root
+}
+
+// FindProjectRoot walks up from the current directory, stopping at $HOME or
+// the filesystem root, and returns the nearest directory containing a
+// project marker along with the marker that matched.
+func FindProjectRoot() (root, marker string) {
 	cwd, err := os.Getwd()
 	if err != nil {
-		return ""
+		return "", ""
 	}
 
 	home, _ := os.UserHomeDir()
 	current := cwd
 
 	for {
-		claudeDir := filepath.Join(current, ".claude")
-		if info, err := os.Stat(claudeDir); err == nil && info.IsDir() {
-			return current
+		for _, name := range ProjectMarkers {
+			info, err := os.Stat(filepath.Join(current, name))
+			if err != nil || (name == ".claude" && !info.IsDir()) {
+				continue
+			}
+			return current, name
 		}
 
 		parent := filepath.Dir(current)
@@ -72,7 +436,7 @@ func GetProjectRoot() string {
 		current = parent
 	}
 
-	return ""
+	return "", ""
 }
 
 // FindConfig searches for configuration file in standard locations.
@@ -90,8 +454,8 @@ func FindConfig() string {
 	}
 
 	// 3. User config directory
-	if home, err := os.UserHomeDir(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(home, UserConfigDir, UserConfigFile))
+	if path, err := UserConfigPath(); err == nil {
+		searchPaths = append(searchPaths, path)
 	}
 
 	for _, path := range searchPaths {
@@ -103,44 +467,122 @@ func FindConfig() string {
 	return ""
 }
 
-// Load loads configuration from a file or searches default locations.
-func Load(configPath string) (*Config, error) {
-	if configPath == "" {
-		configPath = FindConfig()
+// FindConfigs returns all existing config files, lowest precedence first
+// (user, project root, current directory).
+func FindConfigs() []string {
+	var searchPaths []string
+
+	// 1. User config directory
+	if path, err := UserConfigPath(); err == nil {
+		searchPaths = append(searchPaths, path)
 	}
 
-	if configPath == "" {
-		return New(), nil
+	// 2. Project root
+	if projectRoot := GetProjectRoot(); projectRoot != "" {
+		searchPaths = append(searchPaths, filepath.Join(projectRoot, ConfigFilename))
 	}
 
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return New(), nil
+	// 3. Current directory
+	if cwd, err := os.Getwd(); err == nil {
+		searchPaths = append(searchPaths, filepath.Join(cwd, ConfigFilename))
+	}
+
+	var found []string
+	seen := make(map[string]bool)
+	for _, path := range searchPaths {
+		if seen[path] {
+			continue
 		}
-		return nil, err
+		seen[path] = true
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		}
+	}
+
+	return found
+}
+
+// Load loads configuration.
+//
+// With an explicit path only that file is read. Otherwise all discovered
+// files are merged field by field, with the current directory overriding the
+// project root, which overrides the user config.
+func Load(configPath string) (*Config, error) {
+	return load(configPath, false)
+}
+
+// LoadStrict is like Load but fails on keys that do not match a known
+// configuration field, such as misspellings.
+func LoadStrict(configPath string) (*Config, error) {
+	return load(configPath, true)
+}
+
+func load(configPath string, strict bool) (*Config, error) {
+	if configPath != "" {
+		return loadFiles([]string{configPath}, strict)
 	}
+	return loadFiles(FindConfigs(), strict)
+}
 
+// loadFiles merges the given files in order. Missing files are skipped.
+func loadFiles(paths []string, strict bool) (*Config, error) {
 	config := New()
-	if err := yaml.Unmarshal(data, config); err != nil {
+	for _, path := range paths {
+		fileConfig, err := readFile(path, strict)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		mergeConfig(config, fileConfig, path)
+		config.sources = append(config.sources, path)
+		config.insecure = append(config.insecure, fileConfig.insecure...)
+	}
+	return config, nil
+}
+
+// readFile parses a single config file. In strict mode unknown keys are
+// errors.
+func readFile(path string, strict bool) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
 		return nil, err
 	}
 
+	config := New()
+	if err := decode(data, config, strict); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	config.present = presentKeys(data)
+	if info, err := os.Stat(path); err == nil && readableByOthers(info.Mode()) && hasToken(config) {
+		config.insecure = []string{path}
+	}
+	if err := config.PaddleOCR.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := config.Defaults.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, profile := range config.Profiles {
+		if err := profile.Defaults.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: profile %q: %w", path, name, err)
+		}
+	}
+	if err := resolveTokenFiles(config, path); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
 	return config, nil
 }
 
 // Save saves configuration to a file.
 func Save(config *Config, configPath string) error {
 	if configPath == "" {
-		home, err := os.UserHomeDir()
+		path, err := UserConfigPath()
 		if err != nil {
 			return err
 		}
-		configDir := filepath.Join(home, UserConfigDir)
-		if err := os.MkdirAll(configDir, 0755); err != nil {
-			return err
-		}
-		configPath = filepath.Join(configDir, UserConfigFile)
+		configPath = path
 	}
 
 	// Ensure parent directory exists
@@ -153,7 +595,7 @@ func Save(config *Config, configPath string) error {
 		return err
 	}
 
-	return os.WriteFile(configPath, data, 0600)
+	return fileutil.AtomicWrite(configPath, data, FileMode)
 }
 
 // GetConfigLocations returns all possible config locations with their status.
@@ -180,14 +622,14 @@ func GetConfigLocations() []struct {
 	}
 
 	// 2. Project root
-	if projectRoot := GetProjectRoot(); projectRoot != "" {
+	if projectRoot, marker := FindProjectRoot(); projectRoot != "" {
 		path := filepath.Join(projectRoot, ConfigFilename)
 		_, err := os.Stat(path)
 		locations = append(locations, struct {
 			Description string
 			Path        string
 			Exists      bool
-		}{"Project root", path, err == nil})
+		}{fmt.Sprintf("Project root (found %s)", marker), path, err == nil})
 	} else {
 		locations = append(locations, struct {
 			Description string
@@ -197,21 +639,46 @@ func GetConfigLocations() []struct {
 	}
 
 	// 3. User config
-	if home, err := os.UserHomeDir(); err == nil {
-		path := filepath.Join(home, UserConfigDir, UserConfigFile)
+	if path, err := PlatformUserConfigPath(); err == nil {
 		_, err := os.Stat(path)
 		locations = append(locations, struct {
 			Description string
 			Path        string
 			Exists      bool
 		}{"User config", path, err == nil})
+
+		if legacy, legacyErr := LegacyUserConfigPath(); legacyErr == nil && legacy != path && configDirOverride() == "" {
+			_, err := os.Stat(legacy)
+			locations = append(locations, struct {
+				Description string
+				Path        string
+				Exists      bool
+			}{"Legacy user config", legacy, err == nil})
+		}
 	}
 
 	return locations
 }
 
-// GetSavePath returns the save path based on scope.
+// Scopes lists the valid config scopes.
+var Scopes = []string{"user", "project", "local"}
+
+// ValidateScope checks that scope is one of Scopes.
+func ValidateScope(scope string) error {
+	if !slices.Contains(Scopes, scope) {
+		return fmt.Errorf("invalid scope %q (valid: %s)", scope, strings.Join(Scopes, ", "))
+	}
+	return nil
+}
+
+// GetSavePath returns the save path based on scope. It fails for scopes not
+// in Scopes, and with os.ErrNotExist for "project" when no project root is
+// found.
 func GetSavePath(scope string) (string, error) {
+	if err := ValidateScope(scope); err != nil {
+		return "", err
+	}
+
 	switch scope {
 	case "local":
 		cwd, err := os.Getwd()
@@ -226,10 +693,6 @@ func GetSavePath(scope string) (string, error) {
 		}
 		return filepath.Join(projectRoot, ConfigFilename), nil
 	default: // "user"
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return "", err
-		}
-		return filepath.Join(home, UserConfigDir, UserConfigFile), nil
+		return UserConfigPath()
 	}
 }
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// isolateUserDirs points the home and XDG directories at fresh temporary
+// directories and clears any config directory override.
+func isolateUserDirs(t *testing.T) (home, xdg string) {
+	t.Helper()
+	home, xdg = t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv(ConfigDirEnvVar, "")
+	SetUserConfigDir("")
+	return home, xdg
+}
+
+func TestUserConfigPathHonorsXDGConfigHome(t *testing.T) {
+	_, xdg := isolateUserDirs(t)
+
+	want := filepath.Join(xdg, AppName, UserConfigFile)
+	got, err := UserConfigPath()
+	if err != nil {
+		t.Fatalf("UserConfigPath: %v", err)
+	}
+	if got != want {
+		t.Errorf("UserConfigPath() = %q, want %q", got, want)
+	}
+
+	cfg := New()
+	cfg.PaddleOCR.ServerURL = "https://ocr.example.com"
+	if err := Save(cfg, ""); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(want); err != nil {
+		t.Fatalf("Save did not write to $XDG_CONFIG_HOME: %v", err)
+	}
+
+	loaded, err := Load(want)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.PaddleOCR.ServerURL != cfg.PaddleOCR.ServerURL {
+		t.Errorf("server_url = %q, want %q", loaded.PaddleOCR.ServerURL, cfg.PaddleOCR.ServerURL)
+	}
+
+	path, err := GetSavePath("user")
+	if err != nil {
+		t.Fatalf("GetSavePath: %v", err)
+	}
+	if path != want {
+		t.Errorf("GetSavePath(\"user\") = %q, want %q", path, want)
+	}
+}
+
+func TestUserConfigPathIgnoresRelativeXDGConfigHome(t *testing.T) {
+	home, _ := isolateUserDirs(t)
+	t.Setenv("XDG_CONFIG_HOME", "relative/dir")
+
+	want := filepath.Join(home, UserConfigDir, UserConfigFile)
+	got, err := PlatformUserConfigPath()
+	if err != nil {
+		t.Fatalf("PlatformUserConfigPath: %v", err)
+	}
+	if got != want {
+		t.Errorf("PlatformUserConfigPath() = %q, want %q", got, want)
+	}
+}
+
+func TestUserConfigPathPrefersExistingLegacyFile(t *testing.T) {
+	home, xdg := isolateUserDirs(t)
+
+	legacy := filepath.Join(home, UserConfigDir, UserConfigFile)
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("paddleocr: {}\n"), FileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := UserConfigPath()
+	if err != nil {
+		t.Fatalf("UserConfigPath: %v", err)
+	}
+	if got != legacy {
+		t.Errorf("UserConfigPath() = %q, want legacy %q", got, legacy)
+	}
+	if LegacyConfigHint() == "" {
+		t.Error("LegacyConfigHint() is empty with only a legacy file present")
+	}
+
+	// Once the XDG file exists it wins.
+	path := filepath.Join(xdg, AppName, UserConfigFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("paddleocr: {}\n"), FileMode); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := UserConfigPath(); got != path {
+		t.Errorf("UserConfigPath() = %q, want %q", got, path)
+	}
+}
+
+// fakePlatform replaces the platform directory lookups for one test.
+func fakePlatform(t *testing.T, platform, home, configDir string) {
+	t.Helper()
+	oldHome, oldConfig, oldGOOS := userHomeDir, userConfigDir, goos
+	t.Cleanup(func() { userHomeDir, userConfigDir, goos = oldHome, oldConfig, oldGOOS })
+
+	goos = platform
+	userHomeDir = func() (string, error) { return home, nil }
+	userConfigDir = func() (string, error) { return configDir, nil }
+}
+
+func TestPlatformUserConfigPathWindows(t *testing.T) {
+	isolateUserDirs(t)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	home, appData := t.TempDir(), t.TempDir()
+	fakePlatform(t, "windows", home, appData)
+
+	want := filepath.Join(appData, AppName, UserConfigFile)
+	got, err := PlatformUserConfigPath()
+	if err != nil {
+		t.Fatalf("PlatformUserConfigPath: %v", err)
+	}
+	if got != want {
+		t.Errorf("PlatformUserConfigPath() = %q, want %q", got, want)
+	}
+	if got, _ := UserConfigPath(); got != want {
+		t.Errorf("UserConfigPath() = %q, want %q", got, want)
+	}
+
+	// A config left at the old ~/.config location keeps being used, with a
+	// hint to move it, and is listed as a separate location.
+	legacy := filepath.Join(home, UserConfigDir, UserConfigFile)
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(legacy, []byte("paddleocr: {}\n"), FileMode); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := UserConfigPath(); got != legacy {
+		t.Errorf("UserConfigPath() = %q, want legacy %q", got, legacy)
+	}
+	if LegacyConfigHint() == "" {
+		t.Error("LegacyConfigHint() is empty with only a legacy file present")
+	}
+
+	var listed []string
+	for _, loc := range GetConfigLocations() {
+		listed = append(listed, loc.Path)
+	}
+	for _, path := range []string{want, legacy} {
+		if !slices.Contains(listed, path) {
+			t.Errorf("GetConfigLocations() = %q, missing %q", listed, path)
+		}
+	}
+}
+
+func TestPlatformUserConfigPathDarwin(t *testing.T) {
+	isolateUserDirs(t)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	home := t.TempDir()
+	fakePlatform(t, "darwin", home, filepath.Join(home, "Library", "Application Support"))
+
+	// macOS keeps ~/.config rather than ~/Library/Application Support.
+	want := filepath.Join(home, UserConfigDir, UserConfigFile)
+	got, err := PlatformUserConfigPath()
+	if err != nil {
+		t.Fatalf("PlatformUserConfigPath: %v", err)
+	}
+	if got != want {
+		t.Errorf("PlatformUserConfigPath() = %q, want %q", got, want)
+	}
+	if LegacyConfigHint() != "" {
+		t.Errorf("LegacyConfigHint() = %q, want none when both paths agree", LegacyConfigHint())
+	}
+}
+
+func TestPlatformUserConfigPathXDGBeatsPlatform(t *testing.T) {
+	_, xdg := isolateUserDirs(t)
+	fakePlatform(t, "windows", t.TempDir(), t.TempDir())
+
+	want := filepath.Join(xdg, AppName, UserConfigFile)
+	if got, _ := PlatformUserConfigPath(); got != want {
+		t.Errorf("PlatformUserConfigPath() = %q, want %q", got, want)
+	}
+}
+
+func TestSaveReplacesFileAtomically(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFilename)
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := New()
+	cfg.PaddleOCR.ServerURL = "https://ocr.example.com"
+	if err := Save(cfg, path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != FileMode {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), FileMode)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory holds %d entries, want only the config file", len(entries))
+	}
+}
+
+func TestGetSavePathRejectsScopeTypo(t *testing.T) {
+	_, xdg := isolateUserDirs(t)
+
+	for _, scope := range []string{"proj", "Project", "usr", ""} {
+		path, err := GetSavePath(scope)
+		if err == nil {
+			t.Errorf("GetSavePath(%q) = %q, want an error instead of a fallback to the user config", scope, path)
+			continue
+		}
+		for _, valid := range Scopes {
+			if !strings.Contains(err.Error(), valid) {
+				t.Errorf("GetSavePath(%q) error %q does not list %q", scope, err, valid)
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(xdg, AppName)); !os.IsNotExist(err) {
+		t.Errorf("user config directory was touched: %v", err)
+	}
+	if path, err := GetSavePath("user"); err != nil || path != filepath.Join(xdg, AppName, UserConfigFile) {
+		t.Errorf("GetSavePath(\"user\") = %q, %v", path, err)
+	}
+}
@@ -0,0 +1,366 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/fileutil"
+)
+
+// ErrKeyNotFound is returned when a dotted key is not present in a document.
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyInfo describes a known configuration key.
+type KeyInfo struct {
+	Kind   reflect.Kind
+	Secret bool
+}
+
+// LookupKey returns schema information for a dotted key such as
+// "paddleocr.server_url". Map entries (e.g. profile names) match any name.
+func LookupKey(key string) (KeyInfo, bool) {
+	return lookupKey(reflect.TypeOf(Config{}), strings.Split(key, "."))
+}
+
+func lookupKey(t reflect.Type, parts []string) (KeyInfo, bool) {
+	if len(parts) == 0 {
+		return KeyInfo{}, false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || yamlName(field) != parts[0] {
+			continue
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			return lookupKey(field.Type, parts[1:])
+		case reflect.Map:
+			if len(parts) < 2 {
+				return KeyInfo{}, false
+			}
+			if field.Type.Elem().Kind() == reflect.Struct {
+				return lookupKey(field.Type.Elem(), parts[2:])
+			}
+			return KeyInfo{Kind: field.Type.Elem().Kind()}, len(parts) == 2
+		default:
+			if len(parts) != 1 {
+				return KeyInfo{}, false
+			}
+			return KeyInfo{Kind: field.Type.Kind(), Secret: field.Tag.Get("secret") == "true"}, true
+		}
+	}
+	return KeyInfo{}, false
+}
+
+// KnownKeys returns all settable dotted keys. Map entries are shown with a
+// "<name>" placeholder.
+func KnownKeys() []string {
+	var keys []string
+	collectKeys(reflect.TypeOf(Config{}), "", &keys)
+	return keys
+}
+
+func collectKeys(t reflect.Type, prefix string, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := prefix + yamlName(field)
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			collectKeys(field.Type, key+".", keys)
+		case reflect.Map:
+			if field.Type.Elem().Kind() == reflect.Struct {
+				collectKeys(field.Type.Elem(), key+".<name>.", keys)
+			} else {
+				*keys = append(*keys, key+".<name>")
+			}
+		default:
+			*keys = append(*keys, key)
+		}
+	}
+}
+
+// Document is a config file loaded as a YAML node tree, so edits preserve
+// comments, ordering and unknown keys.
+type Document struct {
+	Path string
+	root *yaml.Node
+}
+
+// KeyValue is a flattened dotted key and its scalar value.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// LoadDocument parses a config file for editing. A missing file yields an
+// empty document.
+func LoadDocument(path string) (*Document, error) {
+	doc := &Document{Path: path, root: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return doc, nil
+		}
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(node.Content) > 0 {
+		if node.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s: top level is not a mapping", path)
+		}
+		doc.root = node.Content[0]
+	}
+	return doc, nil
+}
+
+// NewDocument builds a document from an in-memory config.
+func NewDocument(cfg *Config) (*Document, error) {
+	var node yaml.Node
+	if err := node.Encode(cfg); err != nil {
+		return nil, err
+	}
+	return &Document{root: &node}, nil
+}
+
+// Get returns the value of a dotted key. List values are joined with
+// commas.
+func (d *Document) Get(key string) (string, error) {
+	node := d.find(key)
+	if node == nil {
+		return "", ErrKeyNotFound
+	}
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		var items []string
+		for _, item := range node.Content {
+			items = append(items, item.Value)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("%s is a section, not a value", key)
+	}
+}
+
+// Set assigns a value to a dotted key, creating intermediate sections.
+// Values of known keys are checked against their type; list keys take a
+// comma-separated value.
+func (d *Document) Set(key, value string) error {
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if info, ok := LookupKey(key); ok {
+		if info.Kind == reflect.Slice {
+			valueNode = sequenceNode(value)
+		} else {
+			tag, err := scalarTag(info.Kind, value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			valueNode.Tag = tag
+		}
+	}
+
+	parts := strings.Split(key, ".")
+	mapping := d.root
+	for _, part := range parts[:len(parts)-1] {
+		child := mappingValue(mapping, part)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, child)
+		}
+		if child.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s: %s is not a section", key, part)
+		}
+		mapping = child
+	}
+
+	last := parts[len(parts)-1]
+	if existing := mappingValue(mapping, last); existing != nil {
+		if existing.Kind == yaml.MappingNode {
+			return fmt.Errorf("%s is a section, not a value", key)
+		}
+		existing.Kind, existing.Tag, existing.Value, existing.Style = valueNode.Kind, valueNode.Tag, valueNode.Value, valueNode.Style
+		existing.Content = valueNode.Content
+		return nil
+	}
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last}, valueNode)
+	return nil
+}
+
+// Unset removes a dotted key, pruning sections left empty. It reports
+// whether the key was present.
+func (d *Document) Unset(key string) bool {
+	return unsetPath(d.root, strings.Split(key, "."))
+}
+
+func unsetPath(mapping *yaml.Node, parts []string) bool {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != parts[0] {
+			continue
+		}
+
+		if len(parts) == 1 {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return true
+		}
+
+		child := mapping.Content[i+1]
+		if child.Kind != yaml.MappingNode || !unsetPath(child, parts[1:]) {
+			return false
+		}
+		if len(child.Content) == 0 {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		}
+		return true
+	}
+	return false
+}
+
+// List returns all scalar values as dotted keys, sorted by key.
+func (d *Document) List() []KeyValue {
+	var values []KeyValue
+	flatten(d.root, "", &values)
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return values
+}
+
+func flatten(node *yaml.Node, prefix string, values *[]KeyValue) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			flatten(node.Content[i+1], prefix+node.Content[i].Value+".", values)
+		}
+	case yaml.SequenceNode:
+		var items []string
+		for _, item := range node.Content {
+			items = append(items, item.Value)
+		}
+		*values = append(*values, KeyValue{strings.TrimSuffix(prefix, "."), "[" + strings.Join(items, ", ") + "]"})
+	case yaml.ScalarNode:
+		*values = append(*values, KeyValue{strings.TrimSuffix(prefix, "."), node.Value})
+	}
+}
+
+// IsEmpty reports whether the document has no keys.
+func (d *Document) IsEmpty() bool {
+	return len(d.root.Content) == 0
+}
+
+// Validate checks that the document decodes into a valid configuration.
+func (d *Document) Validate() error {
+	var cfg Config
+	if err := d.root.Decode(&cfg); err != nil {
+		return err
+	}
+	if err := cfg.PaddleOCR.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Defaults.Validate(); err != nil {
+		return err
+	}
+	for name, profile := range cfg.Profiles {
+		if err := profile.Defaults.Validate(); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Save writes the document back to its path with owner-only permissions.
+func (d *Document) Save() error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(d.root); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.Path), 0755); err != nil {
+		return err
+	}
+	return fileutil.AtomicWrite(d.Path, buf.Bytes(), FileMode)
+}
+
+// find returns the node at a dotted key, or nil.
+func (d *Document) find(key string) *yaml.Node {
+	node := d.root
+	for _, part := range strings.Split(key, ".") {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		if node = mappingValue(node, part); node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceNode builds a flow-style list of strings from a comma-separated
+// value.
+func sequenceNode(value string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+		}
+	}
+	return node
+}
+
+// scalarTag validates value against kind and returns its YAML tag.
+func scalarTag(kind reflect.Kind, value string) (string, error) {
+	switch kind {
+	case reflect.Int, reflect.Int64:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("expected an integer, got %q", value)
+		}
+		return "!!int", nil
+	case reflect.Float64:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("expected a number, got %q", value)
+		}
+		return "!!float", nil
+	case reflect.Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "", fmt.Errorf("expected true or false, got %q", value)
+		}
+		return "!!bool", nil
+	default:
+		return "!!str", nil
+	}
+}
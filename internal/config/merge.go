@@ -0,0 +1,168 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// merger overlays one configuration onto another.
+type merger struct {
+	// source resolves the origin recorded for a key.
+	source func(key string) string
+	// present reports whether a key was explicitly set in the overlay, so
+	// that zero values such as false or 0 still override.
+	present func(key string) bool
+	origins map[string]string
+}
+
+// mergeConfig overlays the fields of src that are non-zero or explicitly
+// set in its file onto dst, recording source as the origin of every key it
+// overrides.
+func mergeConfig(dst, src *Config, source string) {
+	if len(src.PaddleOCR.Tokens()) > 0 {
+		// A file that sets a token replaces the tokens of lower layers as a
+		// whole, so its access_token is not shadowed by an inherited
+		// access_tokens list, nor its list by an inherited single token.
+		dst.PaddleOCR.AccessToken = ""
+		dst.PaddleOCR.AccessTokens = nil
+		delete(dst.origins, "paddleocr.access_token")
+		delete(dst.origins, "paddleocr.access_tokens")
+		dst.token = src.token
+	}
+	for name, profile := range src.Profiles {
+		existing, ok := dst.Profiles[name]
+		if !ok || profile.AccessToken == "" && profile.AccessTokenEncrypted == "" && profile.AccessTokenFile == "" {
+			continue
+		}
+		// Likewise for a profile's token, which is resolved only when the
+		// profile is applied.
+		existing.AccessToken = ""
+		existing.AccessTokenEncrypted = ""
+		existing.AccessTokenFile = ""
+		dst.Profiles[name] = existing
+		for _, key := range []string{"access_token", "access_token_encrypted", "access_token_file"} {
+			delete(dst.origins, "profiles."+name+"."+key)
+		}
+	}
+	mergeConfigFunc(dst, src, func(string) string { return source }, func(key string) bool { return src.present[key] })
+	if dst.present == nil {
+		dst.present = make(map[string]bool)
+	}
+	for key := range src.present {
+		dst.present[key] = true
+	}
+}
+
+// mergeConfigFunc is like mergeConfig but resolves the origin and presence
+// per key.
+func mergeConfigFunc(dst, src *Config, source func(key string) string, present func(key string) bool) {
+	if dst.origins == nil {
+		dst.origins = make(map[string]string)
+	}
+	m := &merger{source: source, present: present, origins: dst.origins}
+	m.mergeStruct(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem(), "")
+}
+
+// mergeStruct merges exported struct fields, keyed by their YAML names.
+func (m *merger) mergeStruct(dst, src reflect.Value, prefix string) {
+	t := src.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := yamlName(field)
+		if name == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		m.mergeValue(dst.Field(i), src.Field(i), key)
+	}
+}
+
+// mergeValue merges a single value: structs recurse, maps merge per key, and
+// any other value replaces the destination when it is non-zero or was
+// explicitly set.
+func (m *merger) mergeValue(dst, src reflect.Value, key string) {
+	switch src.Kind() {
+	case reflect.Struct:
+		m.mergeStruct(dst, src, key)
+	case reflect.Map:
+		if src.Len() == 0 {
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(src.Type()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			entryKey := fmt.Sprintf("%s.%v", key, iter.Key())
+			if iter.Value().Kind() != reflect.Struct {
+				dst.SetMapIndex(iter.Key(), iter.Value())
+				m.origins[entryKey] = m.source(entryKey)
+				continue
+			}
+
+			entry := reflect.New(iter.Value().Type()).Elem()
+			if existing := dst.MapIndex(iter.Key()); existing.IsValid() {
+				entry.Set(existing)
+			}
+			m.mergeStruct(entry, iter.Value(), entryKey)
+			dst.SetMapIndex(iter.Key(), entry)
+		}
+	default:
+		if src.IsZero() && !m.present(key) {
+			return
+		}
+		dst.Set(src)
+		m.origins[key] = m.source(key)
+	}
+}
+
+// presentKeys returns the dotted keys of every non-null value set in a YAML
+// document, including values equal to their zero value, such as
+// "retries: 0" or "insecure: false".
+func presentKeys(data []byte) map[string]bool {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	keys := make(map[string]bool)
+	collectPresent(root.Content[0], "", keys)
+	return keys
+}
+
+func collectPresent(node *yaml.Node, prefix string, keys map[string]bool) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if value := node.Content[i+1]; value.Tag != "!!null" {
+			keys[key] = true
+			collectPresent(value, key, keys)
+		}
+	}
+}
+
+// yamlName returns the YAML key for a struct field.
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
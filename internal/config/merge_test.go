@@ -0,0 +1,140 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeConfig writes content to name in dir and returns its path.
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), FileMode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFilesPartialOverrides(t *testing.T) {
+	dir := t.TempDir()
+	user := writeConfig(t, dir, "user.yaml", `
+paddleocr:
+    server_url: https://user.example.com
+    access_token: user-token
+    request_fields:
+        file: image
+        fileType: kind
+defaults:
+    timeout: 300
+    retries: 2
+`)
+	project := writeConfig(t, dir, "project.yaml", `
+defaults:
+    retries: 5
+paddleocr:
+    request_fields:
+        fileType: type
+`)
+	local := writeConfig(t, dir, "local.yaml", `
+paddleocr:
+    server_url: https://local.example.com
+`)
+
+	cfg, err := loadFiles([]string{user, project, local}, false)
+	if err != nil {
+		t.Fatalf("loadFiles: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		got    any
+		want   any
+		origin string
+	}{
+		{"paddleocr.server_url", cfg.PaddleOCR.ServerURL, "https://local.example.com", local},
+		{"paddleocr.access_token", cfg.PaddleOCR.AccessToken, "user-token", user},
+		{"paddleocr.request_fields.file", cfg.PaddleOCR.RequestFields["file"], "image", user},
+		{"paddleocr.request_fields.fileType", cfg.PaddleOCR.RequestFields["fileType"], "type", project},
+		{"defaults.timeout", cfg.Defaults.Timeout, 300, user},
+		{"defaults.retries", cfg.Defaults.Retries, 5, project},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+		if origin := cfg.Origin(tt.key); origin != tt.origin {
+			t.Errorf("Origin(%q) = %q, want %q", tt.key, origin, tt.origin)
+		}
+	}
+
+	if sources := cfg.Sources(); len(sources) != 3 || sources[0] != user || sources[2] != local {
+		t.Errorf("Sources() = %q, want user, project, local", sources)
+	}
+}
+
+func TestLoadFilesSkipsMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	user := writeConfig(t, dir, "user.yaml", "paddleocr:\n    server_url: https://user.example.com\n")
+
+	cfg, err := loadFiles([]string{user, filepath.Join(dir, "missing.yaml")}, false)
+	if err != nil {
+		t.Fatalf("loadFiles: %v", err)
+	}
+	if cfg.PaddleOCR.ServerURL != "https://user.example.com" {
+		t.Errorf("server_url = %q", cfg.PaddleOCR.ServerURL)
+	}
+	if len(cfg.Sources()) != 1 {
+		t.Errorf("Sources() = %q, want only the existing file", cfg.Sources())
+	}
+}
+
+func TestLoadExplicitPathIsSingleFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeConfig(t, dir, "only.yaml", "paddleocr:\n    server_url: https://only.example.com\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.PaddleOCR.ServerURL != "https://only.example.com" || cfg.PaddleOCR.AccessToken != "" {
+		t.Errorf("Load(%q) = %+v, want only that file's values", path, cfg.PaddleOCR)
+	}
+	if len(cfg.Sources()) != 1 || cfg.Sources()[0] != path {
+		t.Errorf("Sources() = %q, want [%q]", cfg.Sources(), path)
+	}
+}
+
+func TestLoadFilesResolvesTokensPerLayer(t *testing.T) {
+	dir := t.TempDir()
+	list := writeConfig(t, dir, "list.yaml", "paddleocr:\n    access_tokens: [tok-a, tok-b]\n")
+	single := writeConfig(t, dir, "single.yaml", "paddleocr:\n    access_token: tok-project\n")
+	other := writeConfig(t, dir, "other.yaml", "paddleocr:\n    server_url: https://local.example.com\n")
+
+	tests := []struct {
+		name   string
+		paths  []string
+		want   []string
+		origin string
+	}{
+		{"single token beats a lower list", []string{list, single}, []string{"tok-project"}, single},
+		{"list beats a lower single token", []string{single, list}, []string{"tok-a", "tok-b"}, list},
+		{"file without a token keeps the lower one", []string{list, single, other}, []string{"tok-project"}, single},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadFiles(tt.paths, false)
+			if err != nil {
+				t.Fatalf("loadFiles: %v", err)
+			}
+			if got := cfg.PaddleOCR.Tokens(); !slices.Equal(got, tt.want) {
+				t.Errorf("Tokens() = %q, want %q", got, tt.want)
+			}
+			origin := cfg.Origin("paddleocr.access_token") + cfg.Origin("paddleocr.access_tokens")
+			if origin != tt.origin {
+				t.Errorf("token origin = %q, want %q", origin, tt.origin)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Migration actions for a single key.
+const (
+	// MigrateAdd copies a key missing from the destination.
+	MigrateAdd = "add"
+	// MigrateSame skips a key whose value already matches.
+	MigrateSame = "same"
+	// MigrateKeep keeps the destination's differing value.
+	MigrateKeep = "keep"
+	// MigrateConflict marks differing secrets, which must be resolved
+	// before the migration can be applied.
+	MigrateConflict = "conflict"
+)
+
+// FieldPlan describes what a migration does with one key of the source.
+type FieldPlan struct {
+	Key    string
+	Action string
+	Secret bool
+	// NewValue is set when the source value is rewritten for the
+	// destination: a relative access_token_file is made absolute so it
+	// still names the same file.
+	NewValue string
+	// UseSource is set when a conflict is resolved in favour of the
+	// source value.
+	UseSource bool
+	resolved  bool
+	// group is the section of a token key; all token keys of a section
+	// are compared and resolved together.
+	group string
+}
+
+// tokenKeys are the keys that each supply the access token of a section.
+// Only one of them takes effect, so they are migrated as a unit.
+var tokenKeys = []string{"access_token", "access_token_encrypted", "access_token_file", "access_tokens"}
+
+// tokenGroup returns the section of key if it is one of tokenKeys, such as
+// "paddleocr." or "profiles.work.", and "" otherwise.
+func tokenGroup(key string) string {
+	i := strings.LastIndex(key, ".")
+	section, name := key[:i+1], key[i+1:]
+	if section != "paddleocr." && !(strings.HasPrefix(section, "profiles.") && strings.Count(section, ".") == 2) {
+		return ""
+	}
+	for _, k := range tokenKeys {
+		if name == k {
+			return section
+		}
+	}
+	return ""
+}
+
+// tokenValues returns the token keys set in a section of doc, with relative
+// token files resolved against dir.
+func tokenValues(doc *Document, section, dir string) map[string]string {
+	values := make(map[string]string)
+	for _, k := range tokenKeys {
+		if value, err := doc.Get(section + k); err == nil {
+			if k == "access_token_file" {
+				value = absTokenFile(value, dir)
+			}
+			values[k] = value
+		}
+	}
+	return values
+}
+
+// absTokenFile resolves a relative access_token_file against dir.
+func absTokenFile(file, dir string) string {
+	if file == "" || file == "-" || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
+
+// Migration merges a legacy config file into the canonical location.
+type Migration struct {
+	Source      string
+	Destination string
+	Fields      []FieldPlan
+
+	src *Document
+	dst *Document
+}
+
+// LegacyFiles returns existing config files in locations that are no
+// longer canonical.
+func LegacyFiles() []string {
+	if configDirOverride() != "" {
+		return nil
+	}
+	path, err := PlatformUserConfigPath()
+	if err != nil {
+		return nil
+	}
+	legacy, err := LegacyUserConfigPath()
+	if err != nil || legacy == path {
+		return nil
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return nil
+	}
+	return []string{legacy}
+}
+
+// PlanMigration compares source with destination key by key. Keys missing
+// from the destination are added; differing values keep the destination's,
+// except secrets, which are reported as conflicts. The token keys of a
+// section are compared as a group, so a source access_token is not added
+// next to a destination access_token_encrypted that it would shadow.
+func PlanMigration(source, destination string) (*Migration, error) {
+	src, err := LoadDocument(source)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := LoadDocument(destination)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migration{Source: source, Destination: destination, src: src, dst: dst}
+	srcDir, dstDir := filepath.Dir(source), filepath.Dir(destination)
+	for _, kv := range src.List() {
+		info, _ := LookupKey(kv.Key)
+		field := FieldPlan{Key: kv.Key, Action: MigrateAdd, Secret: info.Secret, group: tokenGroup(kv.Key)}
+
+		srcValue, _ := src.Get(kv.Key)
+		if field.group != "" {
+			if strings.HasSuffix(kv.Key, ".access_token_file") {
+				if abs := absTokenFile(srcValue, srcDir); abs != srcValue {
+					field.NewValue = abs
+				}
+			}
+			srcTokens := tokenValues(src, field.group, srcDir)
+			dstTokens := tokenValues(dst, field.group, dstDir)
+			switch {
+			case len(dstTokens) == 0:
+			case sameValues(srcTokens, dstTokens):
+				field.Action = MigrateSame
+			default:
+				field.Action = MigrateConflict
+			}
+			m.Fields = append(m.Fields, field)
+			continue
+		}
+		if dstValue, err := dst.Get(kv.Key); err == nil {
+			switch {
+			case dstValue == srcValue:
+				field.Action = MigrateSame
+			case info.Secret:
+				field.Action = MigrateConflict
+			default:
+				field.Action = MigrateKeep
+			}
+		}
+		m.Fields = append(m.Fields, field)
+	}
+	return m, nil
+}
+
+// sameValues reports whether two maps hold the same entries.
+func sameValues(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Conflicts returns the keys that still need to be resolved. A token
+// group is listed once, by its first key.
+func (m *Migration) Conflicts() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, f := range m.Fields {
+		if f.Action != MigrateConflict || f.resolved {
+			continue
+		}
+		if f.group != "" {
+			if seen[f.group] {
+				continue
+			}
+			seen[f.group] = true
+		}
+		keys = append(keys, f.Key)
+	}
+	return keys
+}
+
+// Resolve settles a conflict, taking the source value if useSource is set
+// and keeping the destination value otherwise. Resolving a token key
+// settles its whole group.
+func (m *Migration) Resolve(key string, useSource bool) {
+	var group string
+	for _, f := range m.Fields {
+		if f.Key == key {
+			group = f.group
+		}
+	}
+	for i := range m.Fields {
+		f := &m.Fields[i]
+		if f.Action == MigrateConflict && (f.Key == key || group != "" && f.group == group) {
+			f.UseSource = useSource
+			f.resolved = true
+		}
+	}
+}
+
+// BackupPath is where the source file is moved once migrated.
+func (m *Migration) BackupPath() string {
+	return m.Source + ".bak"
+}
+
+// Apply writes the merged destination and renames the source to its backup
+// path. It fails if conflicts remain unresolved or a backup already exists.
+func (m *Migration) Apply() error {
+	if conflicts := m.Conflicts(); len(conflicts) > 0 {
+		return fmt.Errorf("unresolved conflicts: %v", conflicts)
+	}
+	if _, err := os.Stat(m.BackupPath()); err == nil {
+		return fmt.Errorf("backup %s already exists", m.BackupPath())
+	}
+
+	// A token taken from the source replaces every token key of the
+	// destination's section, so none of them can shadow it.
+	for _, f := range m.Fields {
+		if f.group != "" && f.Action == MigrateConflict && f.UseSource {
+			for _, k := range tokenKeys {
+				m.dst.Unset(f.group + k)
+			}
+		}
+	}
+	for _, f := range m.Fields {
+		if f.Action != MigrateAdd && !(f.Action == MigrateConflict && f.UseSource) {
+			continue
+		}
+		value, err := m.src.Get(f.Key)
+		if err != nil {
+			return err
+		}
+		if f.NewValue != "" {
+			value = f.NewValue
+		}
+		if err := m.dst.Set(f.Key, value); err != nil {
+			return err
+		}
+	}
+
+	if err := m.dst.Validate(); err != nil {
+		return err
+	}
+	if err := m.dst.Save(); err != nil {
+		return err
+	}
+	return os.Rename(m.Source, m.BackupPath())
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPlanMigrationTokenGroups(t *testing.T) {
+	tests := []struct {
+		name      string
+		src, dst  string
+		conflicts []string
+	}{
+		{
+			name:      "plaintext against encrypted",
+			src:       "paddleocr:\n    access_token: A\n",
+			dst:       "paddleocr:\n    access_token_encrypted: enc:v1:xyz\n",
+			conflicts: []string{"paddleocr.access_token"},
+		},
+		{
+			name:      "plaintext against token file",
+			src:       "profiles:\n    work:\n        access_token: A\n",
+			dst:       "profiles:\n    work:\n        access_token_file: /run/tok\n",
+			conflicts: []string{"profiles.work.access_token"},
+		},
+		{
+			name: "same token",
+			src:  "paddleocr:\n    access_token: A\n",
+			dst:  "paddleocr:\n    access_token: A\n",
+		},
+		{
+			name: "other section",
+			src:  "paddleocr:\n    access_token: A\n",
+			dst:  "profiles:\n    work:\n        access_token: B\n",
+		},
+		{
+			name:      "one conflict per section",
+			src:       "paddleocr:\n    access_token: A\n    access_token_file: /run/a\n",
+			dst:       "paddleocr:\n    access_tokens: [B, C]\n",
+			conflicts: []string{"paddleocr.access_token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := writeConfig(t, dir, "src.yaml", tt.src)
+			dst := writeConfig(t, dir, "dst.yaml", tt.dst)
+
+			m, err := PlanMigration(src, dst)
+			if err != nil {
+				t.Fatalf("PlanMigration: %v", err)
+			}
+			if got := m.Conflicts(); !slices.Equal(got, tt.conflicts) {
+				t.Errorf("Conflicts() = %v, want %v", got, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestMigrationApplyReplacesTokenGroup(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	src := writeConfig(t, srcDir, "config.yaml", "paddleocr:\n    access_token_file: token\n")
+	dst := writeConfig(t, dstDir, "config.yaml", "paddleocr:\n    access_token: B\n")
+
+	m, err := PlanMigration(src, dst)
+	if err != nil {
+		t.Fatalf("PlanMigration: %v", err)
+	}
+	want := filepath.Join(srcDir, "token")
+	if f := m.Fields[0]; f.NewValue != want {
+		t.Errorf("NewValue = %q, want %q", f.NewValue, want)
+	}
+	if err := m.Apply(); err == nil {
+		t.Fatal("Apply succeeded with an unresolved conflict")
+	}
+
+	m.Resolve("paddleocr.access_token_file", true)
+	if err := m.Apply(); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	doc, err := LoadDocument(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := doc.Get("paddleocr.access_token_file"); got != want {
+		t.Errorf("access_token_file = %q, want %q", got, want)
+	}
+	if _, err := doc.Get("paddleocr.access_token"); err == nil {
+		t.Error("access_token was kept and would shadow the migrated token file")
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import "os"
+
+// FileMode is the mode config files are written with: owner read/write
+// only, as they may hold access tokens. It is not configurable.
+const FileMode os.FileMode = 0600
+
+// readableByOthers reports whether a file mode lets group or others read
+// the file. Permission bits are not meaningful on Windows.
+func readableByOthers(mode os.FileMode) bool {
+	return goos != "windows" && mode.Perm()&0044 != 0
+}
+
+// hasToken reports whether a config file sets an access token directly.
+func hasToken(cfg *Config) bool {
+	if len(cfg.PaddleOCR.Tokens()) > 0 {
+		return true
+	}
+	for _, profile := range cfg.Profiles {
+		if profile.AccessToken != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// InsecureFiles returns the loaded config files that contain an access
+// token but are readable by group or others.
+func (c *Config) InsecureFiles() []string {
+	return c.insecure
+}
+
+// FixPermissions restricts a file to owner read/write if group or others
+// can read it, and reports whether its mode was changed.
+func FixPermissions(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if !readableByOthers(info.Mode()) {
+		return false, nil
+	}
+	return true, os.Chmod(path, FileMode)
+}
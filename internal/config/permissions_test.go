@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// writeWithMode writes content to a new file in dir with exactly mode.
+func writeWithMode(t *testing.T, dir, content string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, ConfigFilename)
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInsecureFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not meaningful on Windows")
+	}
+
+	const withToken = "paddleocr:\n    access_token: secret\n"
+	const withProfileToken = "profiles:\n    prod:\n        access_token: secret\n"
+	const withoutToken = "paddleocr:\n    server_url: https://ocr.example.com\n"
+
+	tests := []struct {
+		name    string
+		content string
+		mode    os.FileMode
+		want    bool
+	}{
+		{"owner only", withToken, 0600, false},
+		{"owner read only", withToken, 0400, false},
+		{"group readable", withToken, 0640, true},
+		{"other readable", withToken, 0604, true},
+		{"world readable", withToken, 0644, true},
+		{"profile token", withProfileToken, 0644, true},
+		{"no token", withoutToken, 0644, false},
+		{"group writable only", withToken, 0620, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeWithMode(t, t.TempDir(), tt.content, tt.mode)
+
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			got := len(cfg.InsecureFiles()) == 1 && cfg.InsecureFiles()[0] == path
+			if got != tt.want {
+				t.Errorf("InsecureFiles() = %q, want insecure %v", cfg.InsecureFiles(), tt.want)
+			}
+		})
+	}
+}
+
+func TestInsecureFilesIgnoredOnWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not meaningful on Windows")
+	}
+	fakePlatform(t, "windows", t.TempDir(), t.TempDir())
+
+	path := writeWithMode(t, t.TempDir(), "paddleocr:\n    access_token: secret\n", 0644)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.InsecureFiles()) != 0 {
+		t.Errorf("InsecureFiles() = %q, want none on Windows", cfg.InsecureFiles())
+	}
+}
+
+func TestFixPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not meaningful on Windows")
+	}
+
+	tests := []struct {
+		mode        os.FileMode
+		wantChanged bool
+		wantMode    os.FileMode
+	}{
+		{0644, true, FileMode},
+		{0640, true, FileMode},
+		{0600, false, 0600},
+		{0400, false, 0400},
+	}
+	for _, tt := range tests {
+		path := writeWithMode(t, t.TempDir(), "paddleocr: {}\n", tt.mode)
+
+		changed, err := FixPermissions(path)
+		if err != nil {
+			t.Fatalf("FixPermissions(%v): %v", tt.mode, err)
+		}
+		if changed != tt.wantChanged {
+			t.Errorf("FixPermissions(%v) changed = %v, want %v", tt.mode, changed, tt.wantChanged)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != tt.wantMode {
+			t.Errorf("mode after FixPermissions(%v) = %v, want %v", tt.mode, info.Mode().Perm(), tt.wantMode)
+		}
+	}
+}
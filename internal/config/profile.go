@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ProfileEnvVar selects a profile when --profile is not given.
+const ProfileEnvVar = "PADDLEOCR_PROFILE"
+
+// Profile holds the settings of a named server profile. Non-empty values
+// override the top-level configuration when the profile is selected.
+type Profile struct {
+	ServerURL            string   `yaml:"server_url,omitempty"`
+	AccessToken          string   `yaml:"access_token,omitempty" secret:"true"`
+	AccessTokenEncrypted string   `yaml:"access_token_encrypted,omitempty" secret:"true"`
+	AccessTokenFile      string   `yaml:"access_token_file,omitempty"`
+	Defaults             Defaults `yaml:"defaults,omitempty"`
+	Options              Options  `yaml:"options,omitempty"`
+}
+
+// ProfileNames returns the configured profile names in sorted order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfile returns the name of the applied profile, or "".
+func (c *Config) ActiveProfile() string {
+	return c.profile
+}
+
+// SelectProfile resolves the profile to use: the explicit name if given,
+// then $PADDLEOCR_PROFILE, then default_profile.
+func (c *Config) SelectProfile(name string) string {
+	if name != "" {
+		return name
+	}
+	if env := os.Getenv(ProfileEnvVar); env != "" {
+		return env
+	}
+	return c.DefaultProfile
+}
+
+// ApplyProfile overlays the selected profile (see SelectProfile) onto the
+// top-level settings. Values set in the profile's file override, even when
+// they are false or 0. The profile's access_token_encrypted or
+// access_token_file is resolved here, so unused profiles are never read. It
+// is a no-op when no profile is selected and fails with the available names
+// when the profile does not exist.
+func (c *Config) ApplyProfile(name string) error {
+	name = c.SelectProfile(name)
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		available := "none"
+		if names := c.ProfileNames(); len(names) > 0 {
+			available = strings.Join(names, ", ")
+		}
+		return fmt.Errorf("profile %q not found (available: %s)", name, available)
+	}
+	if err := c.resolveProfileToken(name, &profile); err != nil {
+		return err
+	}
+
+	overlay := New()
+	overlay.PaddleOCR.ServerURL = profile.ServerURL
+	overlay.PaddleOCR.AccessToken = profile.AccessToken
+	overlay.Defaults = profile.Defaults
+	overlay.Options = profile.Options
+
+	prefix := "profiles." + name + "."
+	profileKey := func(key string) string {
+		return prefix + strings.TrimPrefix(key, "paddleocr.")
+	}
+	origin := func(key string) string {
+		if key == "paddleocr.access_token" && c.origins[prefix+"access_token"] == "" {
+			// The token was read from access_token_encrypted or
+			// access_token_file.
+			if file := c.origins[prefix+"access_token_encrypted"]; file != "" {
+				return file
+			}
+			return c.origins[prefix+"access_token_file"]
+		}
+		return c.origins[profileKey(key)]
+	}
+	mergeConfigFunc(c, overlay, c.overlay("profile", origin),
+		func(key string) bool { return c.present[profileKey(key)] })
+	if profile.AccessToken != "" {
+		// A profile's own token replaces any top-level rotation list.
+		c.PaddleOCR.AccessTokens = nil
+	}
+	c.profile = name
+	return nil
+}
+
+// PresetNames returns the configured preset names in sorted order.
+func (c *Config) PresetNames() []string {
+	names := make([]string, 0, len(c.Presets))
+	for name := range c.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActivePreset returns the name of the applied preset, or "".
+func (c *Config) ActivePreset() string {
+	return c.preset
+}
+
+// ApplyPreset overlays the options of the named preset onto the current
+// options. It is applied after ApplyProfile, so presets override profile
+// options and flags override both. It is a no-op for an empty name and
+// fails with the available names when the preset does not exist.
+func (c *Config) ApplyPreset(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	preset, ok := c.Presets[name]
+	if !ok {
+		available := "none"
+		if names := c.PresetNames(); len(names) > 0 {
+			available = strings.Join(names, ", ")
+		}
+		return fmt.Errorf("preset %q not found (available: %s)", name, available)
+	}
+
+	overlay := New()
+	overlay.Options = preset
+
+	prefix := "presets." + name + "."
+	presetKey := func(key string) string {
+		return prefix + strings.TrimPrefix(key, "options.")
+	}
+	mergeConfigFunc(c, overlay,
+		c.overlay("preset", func(key string) string { return c.origins[presetKey(key)] }),
+		func(key string) bool { return c.present[presetKey(key)] })
+	c.preset = name
+	return nil
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/crypt"
+)
+
+func TestLayerOrderTopLevelProfilePreset(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "")
+	dir := t.TempDir()
+	user := writeConfig(t, dir, "user.yaml", `
+defaults:
+    timeout: 300
+    retries: 3
+options:
+    orientation: true
+    unwarp: true
+    insecure: true
+`)
+	project := writeConfig(t, dir, "project.yaml", `
+profiles:
+    staging:
+        defaults:
+            retries: 0
+        options:
+            unwarp: false
+            insecure: false
+            chart: true
+presets:
+    scans:
+        orientation: false
+        unwarp: true
+`)
+
+	cfg, err := loadFiles([]string{user, project}, false)
+	if err != nil {
+		t.Fatalf("loadFiles: %v", err)
+	}
+	if err := cfg.ApplyProfile("staging"); err != nil {
+		t.Fatalf("ApplyProfile: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		got    any
+		want   any
+		origin string
+	}{
+		{"defaults.timeout", cfg.Defaults.Timeout, 300, user},
+		{"defaults.retries", cfg.Defaults.Retries, 0, project},
+		{"options.orientation", cfg.Options.Orientation, true, user},
+		{"options.unwarp", cfg.Options.Unwarp, false, project},
+		{"options.insecure", cfg.Options.Insecure, false, project},
+		{"options.chart", cfg.Options.Chart, true, project},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("after profile: %s = %v, want %v", tt.key, tt.got, tt.want)
+		}
+		if origin := cfg.Origin(tt.key); origin != tt.origin {
+			t.Errorf("after profile: Origin(%q) = %q, want %q", tt.key, origin, tt.origin)
+		}
+	}
+
+	if err := cfg.ApplyPreset("scans"); err != nil {
+		t.Fatalf("ApplyPreset: %v", err)
+	}
+	want := Options{Orientation: false, Unwarp: true, Chart: true, Insecure: false}
+	if cfg.Options != want {
+		t.Errorf("after preset: options = %+v, want %+v", cfg.Options, want)
+	}
+	if cfg.ActivePreset() != "scans" {
+		t.Errorf("ActivePreset() = %q, want scans", cfg.ActivePreset())
+	}
+}
+
+func TestExplicitZeroOverridesLowerFile(t *testing.T) {
+	dir := t.TempDir()
+	user := writeConfig(t, dir, "user.yaml", "defaults:\n    retries: 3\noptions:\n    insecure: true\n")
+	local := writeConfig(t, dir, "local.yaml", "defaults:\n    retries: 0\noptions:\n    insecure: false\n")
+
+	cfg, err := loadFiles([]string{user, local}, false)
+	if err != nil {
+		t.Fatalf("loadFiles: %v", err)
+	}
+	if cfg.Defaults.Retries != 0 || cfg.Options.Insecure {
+		t.Errorf("retries = %d, insecure = %v, want 0 and false from %s", cfg.Defaults.Retries, cfg.Options.Insecure, local)
+	}
+	if origin := cfg.Origin("options.insecure"); origin != local {
+		t.Errorf("Origin(options.insecure) = %q, want %q", origin, local)
+	}
+
+	// Keys left out, or set to null, do not override.
+	empty := writeConfig(t, dir, "empty.yaml", "defaults:\n    retries:\n")
+	cfg, err = loadFiles([]string{user, empty}, false)
+	if err != nil {
+		t.Fatalf("loadFiles: %v", err)
+	}
+	if cfg.Defaults.Retries != 3 || !cfg.Options.Insecure {
+		t.Errorf("retries = %d, insecure = %v, want 3 and true from %s", cfg.Defaults.Retries, cfg.Options.Insecure, user)
+	}
+}
+
+func TestApplyPresetUnknown(t *testing.T) {
+	cfg := New()
+	cfg.Presets = map[string]Options{"scans": {Unwarp: true}, "charts": {Chart: true}}
+
+	err := cfg.ApplyPreset("scan")
+	if err == nil {
+		t.Fatal("ApplyPreset(\"scan\") succeeded, want an error")
+	}
+	if !strings.Contains(err.Error(), "charts, scans") {
+		t.Errorf("error %q does not list the presets", err)
+	}
+	if err := cfg.ApplyPreset(""); err != nil || cfg.ActivePreset() != "" {
+		t.Errorf("ApplyPreset(\"\") = %v, active %q, want a no-op", err, cfg.ActivePreset())
+	}
+}
+
+func TestProfileTokenResolvedOnlyWhenApplied(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "")
+	dir := t.TempDir()
+	writeConfig(t, dir, "work.token", "work-token\n")
+	user := writeConfig(t, dir, "user.yaml", `
+paddleocr:
+    access_token: top-token
+profiles:
+    other:
+        access_token_file: /nonexistent/tok
+    work:
+        access_token: stale-token
+`)
+	project := writeConfig(t, dir, "project.yaml", `
+profiles:
+    work:
+        access_token_file: work.token
+`)
+
+	cfg, err := loadFiles([]string{user, project}, false)
+	if err != nil {
+		t.Fatalf("loadFiles: %v", err)
+	}
+	if cfg.PaddleOCR.AccessToken != "top-token" {
+		t.Errorf("access_token = %q, want top-token", cfg.PaddleOCR.AccessToken)
+	}
+
+	if err := cfg.ApplyProfile("work"); err != nil {
+		t.Fatalf("ApplyProfile(work): %v", err)
+	}
+	if cfg.PaddleOCR.AccessToken != "work-token" {
+		t.Errorf("access_token = %q, want work-token from the higher layer's file", cfg.PaddleOCR.AccessToken)
+	}
+	if origin := cfg.Origin("paddleocr.access_token"); origin != project {
+		t.Errorf("access_token origin = %q, want %q", origin, project)
+	}
+
+	cfg, err = loadFiles([]string{user, project}, false)
+	if err != nil {
+		t.Fatalf("loadFiles: %v", err)
+	}
+	if err := cfg.ApplyProfile("other"); err == nil || !strings.Contains(err.Error(), `profile "other"`) {
+		t.Errorf("ApplyProfile(other) = %v, want an error naming the profile", err)
+	}
+}
+
+func TestProfileTokensWithDifferentPassphrases(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "")
+	home, err := crypt.Encrypt("home-token", "home-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	work, err := crypt.Encrypt("work-token", "work-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeConfig(t, t.TempDir(), "config.yaml", `
+profiles:
+    home:
+        access_token_encrypted: `+home+`
+    work:
+        access_token_encrypted: `+work+`
+`)
+
+	t.Setenv(PassphraseEnvVar, "work-pass")
+	cfg, err := loadFiles([]string{path}, false)
+	if err != nil {
+		t.Fatalf("loadFiles: %v", err)
+	}
+	if err := cfg.ApplyProfile("work"); err != nil {
+		t.Fatalf("ApplyProfile(work): %v", err)
+	}
+	if cfg.PaddleOCR.AccessToken != "work-token" {
+		t.Errorf("access_token = %q, want work-token", cfg.PaddleOCR.AccessToken)
+	}
+	if key := cfg.TokenKey(); key != "profiles.work.access_token_encrypted" {
+		t.Errorf("TokenKey() = %q, want profiles.work.access_token_encrypted", key)
+	}
+
+	// A replacement is encrypted with the passphrase of the token it
+	// replaces.
+	encrypted, err := cfg.EncryptToken("new-token")
+	if err != nil {
+		t.Fatalf("EncryptToken: %v", err)
+	}
+	if got, err := crypt.Decrypt(encrypted, "work-pass"); err != nil || got != "new-token" {
+		t.Errorf("Decrypt = %q, %v, want new-token", got, err)
+	}
+}
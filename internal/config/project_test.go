@@ -0,0 +1,195 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// chdir changes the working directory for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(old) })
+}
+
+// tempTree returns a fresh temporary directory, with symlinks resolved so
+// it compares equal to os.Getwd, that also serves as $HOME.
+func tempTree(t *testing.T) string {
+	t.Helper()
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", dir)
+	return dir
+}
+
+// mark creates a project marker in dir: a directory for names ending in
+// "/", a file otherwise.
+func mark(t *testing.T, dir, name string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if name[len(name)-1] == '/' {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindProjectRoot(t *testing.T) {
+	tests := []struct {
+		name       string
+		markers    map[string][]string // directory (relative to the tree) -> markers
+		cwd        string
+		wantRoot   string
+		wantMarker string
+	}{
+		{
+			name:       ".claude directory",
+			markers:    map[string][]string{"proj": {".claude/"}},
+			cwd:        "proj/src/pkg",
+			wantRoot:   "proj",
+			wantMarker: ".claude",
+		},
+		{
+			name:       ".git directory",
+			markers:    map[string][]string{"proj": {".git/"}},
+			cwd:        "proj/src",
+			wantRoot:   "proj",
+			wantMarker: ".git",
+		},
+		{
+			name:       ".git file of a worktree",
+			markers:    map[string][]string{"proj": {".git"}},
+			cwd:        "proj/src",
+			wantRoot:   "proj",
+			wantMarker: ".git",
+		},
+		{
+			name:       ".paddleocr-root file",
+			markers:    map[string][]string{"proj": {".paddleocr-root"}},
+			cwd:        "proj/a/b/c",
+			wantRoot:   "proj",
+			wantMarker: ".paddleocr-root",
+		},
+		{
+			name:       "working directory is the root",
+			markers:    map[string][]string{"proj": {".git/"}},
+			cwd:        "proj",
+			wantRoot:   "proj",
+			wantMarker: ".git",
+		},
+		{
+			name:       ".claude beats .git in the same directory",
+			markers:    map[string][]string{"proj": {".paddleocr-root", ".git/", ".claude/"}},
+			cwd:        "proj/src",
+			wantRoot:   "proj",
+			wantMarker: ".claude",
+		},
+		{
+			name:       ".git beats .paddleocr-root in the same directory",
+			markers:    map[string][]string{"proj": {".paddleocr-root", ".git/"}},
+			cwd:        "proj/src",
+			wantRoot:   "proj",
+			wantMarker: ".git",
+		},
+		{
+			name:       "nearest directory wins over a higher-priority marker above",
+			markers:    map[string][]string{"proj": {".claude/"}, "proj/sub": {".paddleocr-root"}},
+			cwd:        "proj/sub/src",
+			wantRoot:   "proj/sub",
+			wantMarker: ".paddleocr-root",
+		},
+		{
+			name:       ".claude file is not a marker",
+			markers:    map[string][]string{"proj": {".git/"}, "proj/sub": {".claude"}},
+			cwd:        "proj/sub",
+			wantRoot:   "proj",
+			wantMarker: ".git",
+		},
+		{
+			name:    "no marker",
+			markers: map[string][]string{},
+			cwd:     "proj/src",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := tempTree(t)
+			for dir, names := range tt.markers {
+				for _, name := range names {
+					mark(t, filepath.Join(tree, dir), name)
+				}
+			}
+			cwd := filepath.Join(tree, tt.cwd)
+			if err := os.MkdirAll(cwd, 0755); err != nil {
+				t.Fatal(err)
+			}
+			chdir(t, cwd)
+
+			wantRoot := ""
+			if tt.wantRoot != "" {
+				wantRoot = filepath.Join(tree, tt.wantRoot)
+			}
+			root, marker := FindProjectRoot()
+			if root != wantRoot || marker != tt.wantMarker {
+				t.Errorf("FindProjectRoot() = %q, %q, want %q, %q", root, marker, wantRoot, tt.wantMarker)
+			}
+		})
+	}
+}
+
+func TestFindProjectRootStopsAtHome(t *testing.T) {
+	tree := tempTree(t)
+	mark(t, tree, ".git/")
+	home := filepath.Join(tree, "home")
+	cwd := filepath.Join(home, "docs")
+	if err := os.MkdirAll(cwd, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	chdir(t, cwd)
+
+	if root, marker := FindProjectRoot(); root != "" {
+		t.Errorf("FindProjectRoot() = %q, %q, want no root above $HOME", root, marker)
+	}
+}
+
+func TestAddProjectMarkers(t *testing.T) {
+	saved := ProjectMarkers
+	t.Cleanup(func() { ProjectMarkers = saved })
+	ProjectMarkers = append([]string(nil), saved...)
+
+	AddProjectMarkers("go.mod", ".git", "")
+	want := []string{".claude", ".git", ".paddleocr-root", "go.mod"}
+	if len(ProjectMarkers) != len(want) {
+		t.Fatalf("ProjectMarkers = %q, want %q", ProjectMarkers, want)
+	}
+	for i := range want {
+		if ProjectMarkers[i] != want[i] {
+			t.Fatalf("ProjectMarkers = %q, want %q", ProjectMarkers, want)
+		}
+	}
+
+	tree := tempTree(t)
+	mark(t, filepath.Join(tree, "proj"), "go.mod")
+	mark(t, tree, ".paddleocr-root")
+	chdir(t, filepath.Join(tree, "proj"))
+	if root, marker := FindProjectRoot(); root != filepath.Join(tree, "proj") || marker != "go.mod" {
+		t.Errorf("FindProjectRoot() = %q, %q, want the go.mod directory", root, marker)
+	}
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/crypt"
+)
+
+// PassphraseEnvVar holds the passphrase for access_token_encrypted.
+const PassphraseEnvVar = "PADDLEOCR_PASSPHRASE"
+
+// PromptPassphrase, when set, is called for the passphrase of an
+// access_token_encrypted value if $PADDLEOCR_PASSPHRASE is not set.
+var PromptPassphrase func() (string, error)
+
+// passphrase returns the passphrase for decrypting access tokens.
+func passphrase() (string, error) {
+	if env := os.Getenv(PassphraseEnvVar); env != "" {
+		return env, nil
+	}
+	if PromptPassphrase == nil {
+		return "", fmt.Errorf("access_token_encrypted is set but $%s is not", PassphraseEnvVar)
+	}
+	return PromptPassphrase()
+}
+
+// ReadTokenFile reads an access token from a file, or from stdin when path
+// is "-". Surrounding whitespace is trimmed. Errors name the file but never
+// include its contents.
+func ReadTokenFile(path string) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file %s: %w", path, err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// resolveToken sets *token from an encrypted value or a token file when it
+// is empty, in that order of precedence. A relative file is resolved against
+// dir. *pass caches the passphrase across calls. It returns the key that
+// supplied the token, or "" if none did.
+func resolveToken(token *string, encrypted, file, dir string, pass *string) (string, error) {
+	if *token != "" {
+		return "access_token", nil
+	}
+	if encrypted != "" {
+		if *pass == "" {
+			var err error
+			if *pass, err = passphrase(); err != nil {
+				return "", err
+			}
+		}
+		value, err := crypt.Decrypt(encrypted, *pass)
+		if errors.Is(err, crypt.ErrWrongPassphrase) {
+			return "", fmt.Errorf("cannot decrypt access_token_encrypted: %w", err)
+		}
+		if err != nil {
+			return "", err
+		}
+		*token = value
+		return "access_token_encrypted", nil
+	}
+	if file == "" {
+		return "", nil
+	}
+	if file != "-" && !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	value, err := ReadTokenFile(file)
+	if err != nil {
+		return "", err
+	}
+	*token = value
+	return "access_token_file", nil
+}
+
+// resolveTokenFiles decrypts access_token_encrypted and reads
+// access_token_file in the paddleocr section of a config loaded from
+// configPath, and decrypts an encrypted totp_secret. Profile tokens are left
+// alone until the profile is applied, so a profile that is not used cannot
+// break loading.
+func resolveTokenFiles(cfg *Config, configPath string) error {
+	var pass string
+	key, err := resolveToken(&cfg.PaddleOCR.AccessToken, cfg.PaddleOCR.AccessTokenEncrypted, cfg.PaddleOCR.AccessTokenFile, filepath.Dir(configPath), &pass)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		cfg.token = tokenSource{key: "paddleocr." + key, passphrase: pass}
+	}
+	if crypt.IsEncrypted(cfg.PaddleOCR.TOTPSecret) {
+		if pass == "" {
+			if pass, err = passphrase(); err != nil {
+				return err
+			}
+		}
+		secret, err := crypt.Decrypt(cfg.PaddleOCR.TOTPSecret, pass)
+		if errors.Is(err, crypt.ErrWrongPassphrase) {
+			return fmt.Errorf("cannot decrypt totp_secret: %w", err)
+		}
+		if err != nil {
+			return err
+		}
+		cfg.PaddleOCR.TOTPSecret = secret
+	}
+	return nil
+}
+
+// resolveProfileToken resolves the token of the named profile. A relative
+// access_token_file is resolved against the file that set it.
+func (c *Config) resolveProfileToken(name string, profile *Profile) error {
+	prefix := "profiles." + name + "."
+	dir := filepath.Dir(c.origins[prefix+"access_token_file"])
+	var pass string
+	key, err := resolveToken(&profile.AccessToken, profile.AccessTokenEncrypted, profile.AccessTokenFile, dir, &pass)
+	if err != nil {
+		source := c.origins[prefix+"access_token_file"]
+		if profile.AccessTokenEncrypted != "" {
+			source = c.origins[prefix+"access_token_encrypted"]
+		}
+		return fmt.Errorf("%s: profile %q: %w", source, name, err)
+	}
+	if key != "" {
+		c.token = tokenSource{key: prefix + key, passphrase: pass}
+	}
+	return nil
+}
+
+// tokenSource records where the effective access token came from.
+type tokenSource struct {
+	// key is the dotted key that supplied the token, such as
+	// "profiles.work.access_token_encrypted".
+	key string
+	// passphrase decrypted the token, if it was encrypted.
+	passphrase string
+}
+
+// TokenKey returns the dotted key that supplied the access token, such as
+// "paddleocr.access_token" or "profiles.work.access_token_file".
+func (c *Config) TokenKey() string {
+	if c.token.key == "" {
+		return "paddleocr.access_token"
+	}
+	return c.token.key
+}
+
+// EncryptToken encrypts a replacement for a token read from
+// access_token_encrypted, with the passphrase that decrypted it.
+func (c *Config) EncryptToken(token string) (string, error) {
+	pass := c.token.passphrase
+	if pass == "" {
+		var err error
+		if pass, err = passphrase(); err != nil {
+			return "", err
+		}
+	}
+	return crypt.Encrypt(token, pass)
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/crypt"
+)
+
+// Problem severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is an issue found while validating a config file. Line is 0 when
+// the issue is not tied to a line.
+type Problem struct {
+	Line     int    `json:"line,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Key != "" {
+		b.WriteString(p.Key + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// Validation is the result of validating one config file.
+type Validation struct {
+	File     string    `json:"file"`
+	Valid    bool      `json:"valid"`
+	Problems []Problem `json:"problems"`
+}
+
+// Patterns for the messages of yaml errors.
+var (
+	lineError    = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownField = regexp.MustCompile(`^field (\S+) not found in type`)
+)
+
+// decode unmarshals a config file, rejecting unknown keys when strict.
+func decode(data []byte, cfg *Config, strict bool) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(strict)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// ValidateFile checks a config file: it parses it strictly, reporting
+// unknown keys and type mismatches with line numbers, then checks server
+// URLs, tokens, referenced files and value ranges. The file is valid when
+// no problem has error severity.
+func ValidateFile(path string) (*Validation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Validation{File: path, Problems: []Problem{}}
+	cfg := New()
+	if err := decode(data, cfg, true); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			// Syntax errors stop decoding altogether.
+			v.add(parseProblem(err.Error()))
+			return v, nil
+		}
+		for _, msg := range typeErr.Errors {
+			v.add(parseProblem(msg))
+		}
+	}
+
+	doc, err := LoadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	line := func(key string) int {
+		if node := doc.find(key); node != nil {
+			return node.Line
+		}
+		return 0
+	}
+	errorf := func(key, format string, args ...interface{}) {
+		v.add(Problem{Line: line(key), Key: key, Severity: SeverityError, Message: fmt.Sprintf(format, args...)})
+	}
+
+	checkURL := func(key, raw string) {
+		if err := checkServerURL(raw); err != nil {
+			errorf(key, "%v", err)
+		}
+	}
+	if cfg.PaddleOCR.ServerURL != "" {
+		checkURL("paddleocr.server_url", cfg.PaddleOCR.ServerURL)
+	}
+	for _, raw := range cfg.PaddleOCR.ServerURLs {
+		checkURL("paddleocr.server_urls", raw)
+	}
+	for _, name := range cfg.ProfileNames() {
+		if raw := cfg.Profiles[name].ServerURL; raw != "" {
+			checkURL("profiles."+name+".server_url", raw)
+		}
+	}
+
+	if cfg.MetricsURL != "" {
+		checkURL("metrics_url", cfg.MetricsURL)
+	}
+
+	checkToken := func(prefix, token, encrypted, file string) {
+		if doc.find(prefix+"access_token") != nil {
+			if strings.TrimSpace(token) == "" {
+				if file == "" && encrypted == "" {
+					errorf(prefix+"access_token", "access token is empty")
+				}
+			} else if isPlaceholderToken(token) {
+				errorf(prefix+"access_token", "access token looks like a placeholder")
+			}
+		}
+		if encrypted != "" && !crypt.IsEncrypted(encrypted) {
+			errorf(prefix+"access_token_encrypted", "value is not an encrypted token (expected prefix %q)", crypt.Prefix)
+		}
+		if file != "" && file != "-" {
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			if _, err := os.Stat(file); err != nil {
+				errorf(prefix+"access_token_file", "token file %s does not exist", file)
+			}
+		}
+	}
+	checkToken("paddleocr.", cfg.PaddleOCR.AccessToken, cfg.PaddleOCR.AccessTokenEncrypted, cfg.PaddleOCR.AccessTokenFile)
+	for i, token := range cfg.PaddleOCR.AccessTokens {
+		if strings.TrimSpace(token) == "" {
+			errorf("paddleocr.access_tokens", "access token #%d is empty", i+1)
+		} else if isPlaceholderToken(token) {
+			errorf("paddleocr.access_tokens", "access token #%d looks like a placeholder", i+1)
+		}
+	}
+	for _, name := range cfg.ProfileNames() {
+		profile := cfg.Profiles[name]
+		checkToken("profiles."+name+".", profile.AccessToken, profile.AccessTokenEncrypted, profile.AccessTokenFile)
+	}
+
+	// A plaintext token in a file under version control is one commit away
+	// from being published.
+	if inGitRepo(path) {
+		plaintext := func(key, token string) {
+			if strings.TrimSpace(token) != "" && !isPlaceholderToken(token) {
+				v.add(Problem{Line: line(key), Key: key, Severity: SeverityWarning,
+					Message: "plaintext token in a git repository; use access_token_file or 'paddleocr-cli configure --encrypt-token'"})
+			}
+		}
+		plaintext("paddleocr.access_token", cfg.PaddleOCR.AccessToken)
+		for _, token := range cfg.PaddleOCR.AccessTokens {
+			plaintext("paddleocr.access_tokens", token)
+		}
+		for _, name := range cfg.ProfileNames() {
+			plaintext("profiles."+name+".access_token", cfg.Profiles[name].AccessToken)
+		}
+	}
+
+	// The profile may be defined in another config layer, so this is only
+	// a warning.
+	if name := cfg.DefaultProfile; name != "" {
+		if _, ok := cfg.Profiles[name]; !ok {
+			v.add(Problem{Line: line("default_profile"), Key: "default_profile", Severity: SeverityWarning,
+				Message: fmt.Sprintf("profile %q is not defined in this file", name)})
+		}
+	}
+
+	if secret := cfg.PaddleOCR.TOTPSecret; secret != "" && !crypt.IsEncrypted(secret) {
+		if !isBase32(secret) {
+			errorf("paddleocr.totp_secret", "TOTP secret is not valid base32")
+		} else {
+			v.add(Problem{Line: line("paddleocr.totp_secret"), Key: "paddleocr.totp_secret", Severity: SeverityWarning,
+				Message: "TOTP secret is stored in plaintext; store it encrypted with 'paddleocr-cli configure --totp-secret'"})
+		}
+	}
+
+	if err := cfg.PaddleOCR.Validate(); err != nil {
+		// The message starts with the offending key.
+		key, _, _ := strings.Cut(err.Error(), " ")
+		v.add(Problem{Line: line(key), Severity: SeverityError, Message: err.Error()})
+	}
+	if err := cfg.Defaults.Validate(); err != nil {
+		v.add(Problem{Severity: SeverityError, Message: err.Error()})
+	}
+	for _, name := range cfg.ProfileNames() {
+		profile := cfg.Profiles[name]
+		if err := profile.Defaults.Validate(); err != nil {
+			v.add(Problem{Severity: SeverityError, Message: fmt.Sprintf("profile %q: %v", name, err)})
+		}
+	}
+
+	if len(cfg.PaddleOCR.Servers()) == 0 && len(cfg.Profiles) == 0 {
+		v.add(Problem{Severity: SeverityWarning, Message: "no server_url is set"})
+	}
+
+	sort.SliceStable(v.Problems, func(i, j int) bool {
+		return v.Problems[i].Line < v.Problems[j].Line
+	})
+	v.Valid = true
+	for _, p := range v.Problems {
+		if p.Severity == SeverityError {
+			v.Valid = false
+		}
+	}
+	return v, nil
+}
+
+func (v *Validation) add(p Problem) {
+	v.Problems = append(v.Problems, p)
+}
+
+// parseProblem turns a yaml error message into a Problem, extracting the
+// line number when present.
+func parseProblem(msg string) Problem {
+	p := Problem{Severity: SeverityError, Message: strings.TrimPrefix(msg, "yaml: ")}
+	if m := lineError.FindStringSubmatch(msg); m != nil {
+		p.Line, _ = strconv.Atoi(m[1])
+		p.Message = m[2]
+	}
+	if m := unknownField.FindStringSubmatch(p.Message); m != nil {
+		p.Message = fmt.Sprintf("unknown key %q", m[1])
+	}
+	return p
+}
+
+// checkServerURL reports whether raw is an absolute http(s) URL with a host.
+func checkServerURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %v", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL %q must start with http:// or https://", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL %q has no host", raw)
+	}
+	return nil
+}
+
+// inGitRepo reports whether path lies in a git working tree.
+func inGitRepo(path string) bool {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
+// isBase32 reports whether secret decodes as base32, the encoding of TOTP
+// secrets, ignoring case and padding.
+func isBase32(secret string) bool {
+	secret = strings.TrimRight(strings.ToUpper(strings.TrimSpace(secret)), "=")
+	_, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	return err == nil && secret != ""
+}
+
+// placeholderTokens are values commonly left in copied example configs.
+var placeholderTokens = []string{
+	"token", "access_token", "your_token", "your-token", "your_access_token",
+	"your-access-token", "changeme", "change_me", "replace_me", "todo", "xxx",
+}
+
+// isPlaceholderToken reports whether a token looks like an unfilled
+// template value.
+func isPlaceholderToken(token string) bool {
+	t := strings.ToLower(strings.TrimSpace(token))
+	if slices.Contains(placeholderTokens, t) {
+		return true
+	}
+	if strings.HasPrefix(t, "<") && strings.HasSuffix(t, ">") {
+		return true
+	}
+	return strings.Trim(t, "x*.") == ""
+}
@@ -0,0 +1,97 @@
+// Package crypt encrypts access tokens at rest with a passphrase.
+//
+// An encrypted token is Prefix followed by the base64 encoding of a 16-byte
+// salt, a 24-byte nonce and a NaCl secretbox sealed with a key derived from
+// the passphrase by scrypt (N=32768, r=8, p=1). This keeps the token out of
+// plaintext on disk, but it is only as strong as the passphrase.
+package crypt
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Prefix marks an encrypted token and its format version.
+const Prefix = "secretbox:v1:"
+
+const (
+	saltSize  = 16
+	nonceSize = 24
+)
+
+// ErrWrongPassphrase is returned when a token cannot be decrypted with the
+// given passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted ciphertext")
+
+// IsEncrypted reports whether s looks like an encrypted token.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// Encrypt seals plaintext with a key derived from passphrase.
+func Encrypt(plaintext, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", errors.New("passphrase is empty")
+	}
+
+	var salt [saltSize]byte
+	var nonce [nonceSize]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return "", err
+	}
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", err
+	}
+	key, err := deriveKey(passphrase, salt[:])
+	if err != nil {
+		return "", err
+	}
+
+	out := append(salt[:], nonce[:]...)
+	out = secretbox.Seal(out, []byte(plaintext), &nonce, key)
+	return Prefix + base64.StdEncoding.EncodeToString(out), nil
+}
+
+// Decrypt opens a token sealed by Encrypt. It returns ErrWrongPassphrase
+// when the passphrase does not match.
+func Decrypt(ciphertext, passphrase string) (string, error) {
+	if !IsEncrypted(ciphertext) {
+		return "", fmt.Errorf("not an encrypted token (expected prefix %q)", Prefix)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, Prefix))
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted token: %v", err)
+	}
+	if len(data) < saltSize+nonceSize+secretbox.Overhead {
+		return "", errors.New("malformed encrypted token: too short")
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], data[saltSize:saltSize+nonceSize])
+	key, err := deriveKey(passphrase, data[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	plaintext, ok := secretbox.Open(nil, data[saltSize+nonceSize:], &nonce, key)
+	if !ok {
+		return "", ErrWrongPassphrase
+	}
+	return string(plaintext), nil
+}
+
+// deriveKey stretches a passphrase into a secretbox key.
+func deriveKey(passphrase string, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
@@ -0,0 +1,207 @@
+// Package fileutil provides file helpers for PaddleOCR CLI inputs and outputs.
+package fileutil
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SupportedExtensions lists the file extensions accepted for OCR.
+var SupportedExtensions = []string{".pdf", ".png", ".jpg", ".jpeg", ".bmp", ".tiff", ".tif", ".webp"}
+
+// ExtractOptions controls how archives are extracted.
+type ExtractOptions struct {
+	// Recursive allows entries inside subdirectories of the archive.
+	Recursive bool
+	// MaxSize caps the total extracted size in bytes (0 = unlimited).
+	MaxSize int64
+	// Since skips entries last modified before it, when non-zero.
+	Since time.Time
+}
+
+// IsSupported reports whether the file has a supported OCR extension.
+func IsSupported(filePath string) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	for _, supported := range SupportedExtensions {
+		if ext == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// IsZIP reports whether the path looks like a ZIP archive.
+func IsZIP(filePath string) bool {
+	return strings.ToLower(filepath.Ext(filePath)) == ".zip"
+}
+
+// IsArchive reports whether the path looks like a supported archive.
+func IsArchive(filePath string) bool {
+	return IsZIP(filePath) || IsTAR(filePath)
+}
+
+// ExtractArchive extracts a ZIP or TAR archive based on its extension.
+func ExtractArchive(archivePath, destDir string, opts ExtractOptions) ([]string, error) {
+	if IsTAR(archivePath) {
+		return ExtractTARWithOptions(archivePath, destDir, opts)
+	}
+	return ExtractZIPWithOptions(archivePath, destDir, opts)
+}
+
+// ExtractZIP extracts supported files from a flat ZIP archive into destDir.
+func ExtractZIP(zipPath, destDir string) ([]string, error) {
+	return ExtractZIPWithOptions(zipPath, destDir, ExtractOptions{})
+}
+
+// ExtractZIPWithOptions extracts supported image/PDF files from a ZIP archive
+// into destDir and returns their paths sorted by archive name.
+func ExtractZIPWithOptions(zipPath, destDir string, opts ExtractOptions) ([]string, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	ex := newExtraction(destDir, opts)
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			if err := ex.checkDir(file.Name); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := ex.addFile(file.Name, file.Modified, file.Open); err != nil {
+			return nil, err
+		}
+	}
+
+	return ex.paths(), nil
+}
+
+// extraction accumulates the supported files extracted from an archive and
+// applies the shared layout and size checks.
+type extraction struct {
+	destDir   string
+	opts      ExtractOptions
+	limiter   *sizeLimiter
+	extracted map[string]string
+}
+
+func newExtraction(destDir string, opts ExtractOptions) *extraction {
+	return &extraction{
+		destDir:   destDir,
+		opts:      opts,
+		limiter:   &sizeLimiter{max: opts.MaxSize},
+		extracted: make(map[string]string),
+	}
+}
+
+// checkDir rejects directory entries unless recursive extraction is enabled.
+func (e *extraction) checkDir(name string) error {
+	if !e.opts.Recursive {
+		return fmt.Errorf("archive contains directory %q (use --recursive)", name)
+	}
+	return nil
+}
+
+// addFile extracts a regular file entry if it has a supported extension
+// and was modified no earlier than opts.Since.
+func (e *extraction) addFile(name string, modTime time.Time, open func() (io.ReadCloser, error)) error {
+	target, err := entryTarget(e.destDir, name, e.opts)
+	if err != nil {
+		return err
+	}
+	if !IsSupported(name) || modTime.Before(e.opts.Since) {
+		return nil
+	}
+
+	src, err := open()
+	if err != nil {
+		return err
+	}
+	err = writeEntry(target, src, e.limiter)
+	src.Close()
+	if err != nil {
+		return err
+	}
+
+	e.extracted[name] = target
+	return nil
+}
+
+// paths returns the extracted file paths sorted by archive name.
+func (e *extraction) paths() []string {
+	names := make([]string, 0, len(e.extracted))
+	for name := range e.extracted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, e.extracted[name])
+	}
+	return paths
+}
+
+// entryTarget validates an archive entry name and returns its destination path.
+func entryTarget(destDir, name string, opts ExtractOptions) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("archive entry %q escapes the destination directory", name)
+	}
+	if strings.Contains(clean, "/") && !opts.Recursive {
+		return "", fmt.Errorf("archive contains nested entry %q (use --recursive)", name)
+	}
+	return filepath.Join(destDir, filepath.FromSlash(clean)), nil
+}
+
+// writeEntry copies an archive entry to target, enforcing the size limit.
+func writeEntry(target string, src io.Reader, limiter *sizeLimiter) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, limiter.wrap(src))
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// sizeLimiter tracks the total number of bytes extracted from an archive.
+type sizeLimiter struct {
+	max   int64
+	total int64
+}
+
+func (l *sizeLimiter) wrap(r io.Reader) io.Reader {
+	return &limitedReader{r: r, limiter: l}
+}
+
+type limitedReader struct {
+	r       io.Reader
+	limiter *sizeLimiter
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.limiter.total += int64(n)
+	if lr.limiter.max > 0 && lr.limiter.total > lr.limiter.max {
+		return n, fmt.Errorf("extracted size exceeds limit of %d bytes", lr.limiter.max)
+	}
+	return n, err
+}
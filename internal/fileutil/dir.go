@@ -0,0 +1,52 @@
+package fileutil
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// CollectFiles returns the supported files in dir, sorted by path. Files in
+// subdirectories are included only when recursive is set.
+func CollectFiles(dir string, recursive bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Type().IsRegular() && IsSupported(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// ModifiedSince returns the files last modified no earlier than since,
+// keeping their order.
+func ModifiedSince(files []string, since time.Time) ([]string, error) {
+	var recent []string
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if !info.ModTime().Before(since) {
+			recent = append(recent, file)
+		}
+	}
+	return recent, nil
+}
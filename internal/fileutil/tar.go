@@ -0,0 +1,109 @@
+package fileutil
+
+import (
+	"archive/tar"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// tarSuffixes lists recognized TAR archive suffixes.
+var tarSuffixes = []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar.xz", ".txz"}
+
+// IsTAR reports whether the path looks like a (possibly compressed) TAR archive.
+func IsTAR(filePath string) bool {
+	lower := strings.ToLower(filePath)
+	for _, suffix := range tarSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractTAR extracts supported files from a flat TAR archive into destDir.
+func ExtractTAR(tarPath, destDir string) ([]string, error) {
+	return ExtractTARWithOptions(tarPath, destDir, ExtractOptions{})
+}
+
+// ExtractTARWithOptions extracts supported image/PDF files from a TAR archive,
+// optionally gzip, bzip2 or xz compressed, into destDir and returns their
+// paths sorted by archive name.
+func ExtractTARWithOptions(tarPath, destDir string, opts ExtractOptions) ([]string, error) {
+	file, err := os.Open(tarPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stream, err := decompressor(tarPath, file)
+	if err != nil {
+		return nil, err
+	}
+
+	ex := newExtraction(destDir, opts)
+	reader := tar.NewReader(stream)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := ex.checkDir(header.Name); err != nil {
+				return nil, err
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			if err := checkLink(header); err != nil {
+				return nil, err
+			}
+		case tar.TypeReg:
+			open := func() (io.ReadCloser, error) { return io.NopCloser(reader), nil }
+			if err := ex.addFile(header.Name, header.ModTime, open); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ex.paths(), nil
+}
+
+// decompressor wraps r according to the archive's compression suffix.
+func decompressor(tarPath string, r io.Reader) (io.Reader, error) {
+	lower := strings.ToLower(tarPath)
+	switch {
+	case strings.HasSuffix(lower, ".gz"), strings.HasSuffix(lower, ".tgz"):
+		return gzip.NewReader(r)
+	case strings.HasSuffix(lower, ".bz2"), strings.HasSuffix(lower, ".tbz2"):
+		return bzip2.NewReader(r), nil
+	case strings.HasSuffix(lower, ".xz"), strings.HasSuffix(lower, ".txz"):
+		return xz.NewReader(r)
+	default:
+		return r, nil
+	}
+}
+
+// checkLink rejects links whose target resolves outside the destination.
+// Links are never materialized; only their targets are validated.
+func checkLink(header *tar.Header) error {
+	target := strings.ReplaceAll(header.Linkname, "\\", "/")
+	if header.Typeflag == tar.TypeSymlink && !path.IsAbs(target) {
+		target = path.Join(path.Dir(strings.ReplaceAll(header.Name, "\\", "/")), target)
+	}
+
+	clean := path.Clean(target)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("archive link %q points outside the destination directory (%s)", header.Name, header.Linkname)
+	}
+	return nil
+}
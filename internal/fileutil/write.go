@@ -0,0 +1,105 @@
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CollisionMode says what SafeWrite does when the target file exists.
+type CollisionMode int
+
+const (
+	// CollisionOverwrite replaces the existing file.
+	CollisionOverwrite CollisionMode = iota
+	// CollisionSkip leaves the existing file alone.
+	CollisionSkip
+	// CollisionBackup renames the existing file to path+".bak" first,
+	// replacing any older backup.
+	CollisionBackup
+	// CollisionError leaves the existing file alone and fails.
+	CollisionError
+)
+
+// collisionNames are the names of the collision modes, in order.
+var collisionNames = []string{"overwrite", "skip", "backup", "error"}
+
+// CollisionModeNames returns the names accepted by ParseCollisionMode.
+func CollisionModeNames() []string {
+	return append([]string(nil), collisionNames...)
+}
+
+// ParseCollisionMode parses a collision mode name: overwrite, skip, backup
+// or error.
+func ParseCollisionMode(name string) (CollisionMode, error) {
+	for i, modeName := range collisionNames {
+		if name == modeName {
+			return CollisionMode(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid mode %q (valid: %s)", name, strings.Join(collisionNames, ", "))
+}
+
+var (
+	// ErrSkipped is returned by SafeWrite in CollisionSkip mode when the
+	// target file already exists.
+	ErrSkipped = errors.New("file exists; skipped")
+	// ErrExists is returned, wrapped with the path, by SafeWrite in
+	// CollisionError mode when the target file already exists.
+	ErrExists = errors.New("file already exists")
+)
+
+// AtomicWrite writes data to path so that readers only ever see the old
+// content or the complete new content: it writes a temporary file in the
+// same directory and renames it over path. The temporary file is removed on
+// failure.
+func AtomicWrite(path string, data []byte, perm os.FileMode) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// DefaultFileMode is the default mode of output files.
+const DefaultFileMode os.FileMode = 0644
+
+// SafeWrite writes data to path with AtomicWrite and permissions perm,
+// handling an existing file according to mode.
+func SafeWrite(path string, data []byte, mode CollisionMode, perm os.FileMode) error {
+	if mode != CollisionOverwrite {
+		if _, err := os.Stat(path); err == nil {
+			switch mode {
+			case CollisionSkip:
+				return ErrSkipped
+			case CollisionError:
+				return fmt.Errorf("%s: %w", path, ErrExists)
+			}
+			if err := os.Rename(path, path+".bak"); err != nil {
+				return err
+			}
+		}
+	}
+	return AtomicWrite(path, data, perm)
+}
@@ -0,0 +1,162 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Correction is one rule of a corrections file, replacing a systematic OCR
+// misreading.
+type Correction struct {
+	// Line is the rule's line in its file, for messages.
+	Line int
+	// Literal is the text a literal rule replaces, or "" for a regex rule.
+	Literal string
+	// Pattern is the compiled expression of a regex rule, or the escaped
+	// literal of a literal rule.
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// LoadCorrections reads the rules of a corrections file (see
+// ParseCorrections).
+func LoadCorrections(path string) ([]Correction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rules, err := ParseCorrections(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseCorrections parses correction rules, one per line:
+//
+//	wrong=>right        literal, matched as a whole word
+//	/regexp/=>right     Go regular expression; right may use $1 or ${name}
+//
+// Space around "=>" is ignored. Blank lines and lines starting with # are
+// skipped. Errors are prefixed with the line number.
+func ParseCorrections(r io.Reader) ([]Correction, error) {
+	var rules []Correction
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := Correction{Line: n}
+		if strings.HasPrefix(line, "/") {
+			pattern, right, ok := cutRegexRule(line)
+			if !ok {
+				return nil, fmt.Errorf("%d: regex rule must look like /regexp/=>replacement", n)
+			}
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %v", n, err)
+			}
+			if pattern == "" {
+				return nil, fmt.Errorf("%d: empty regex", n)
+			}
+			rule.Pattern = re
+			rule.Replacement = strings.TrimSpace(right)
+		} else {
+			wrong, right, ok := strings.Cut(line, "=>")
+			wrong = strings.TrimSpace(wrong)
+			if !ok || wrong == "" {
+				return nil, fmt.Errorf("%d: rule must look like wrong=>right", n)
+			}
+			rule.Literal = wrong
+			rule.Pattern = regexp.MustCompile(regexp.QuoteMeta(wrong))
+			rule.Replacement = strings.TrimSpace(right)
+		}
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// cutRegexRule splits a /regexp/=>right rule at the first "/" that is
+// followed by "=>", allowing space between the two.
+func cutRegexRule(line string) (pattern, right string, ok bool) {
+	for i := 1; i < len(line); i++ {
+		if line[i] != '/' {
+			continue
+		}
+		if rest := strings.TrimLeft(line[i+1:], " \t"); strings.HasPrefix(rest, "=>") {
+			return line[1:i], rest[2:], true
+		}
+	}
+	return "", "", false
+}
+
+// ApplyCorrections applies rules to markdown in order, each to the result
+// of the previous one, and returns the corrected text with the number of
+// replacements made.
+func ApplyCorrections(markdown string, rules []Correction) (string, int) {
+	total := 0
+	for _, rule := range rules {
+		var n int
+		markdown, n = rule.apply(markdown)
+		total += n
+	}
+	return markdown, total
+}
+
+// apply replaces the rule's matches in s. A literal only matches where it
+// is not part of a longer word: an end that is a word character must not
+// touch another one (see isWordRune).
+func (c Correction) apply(s string) (string, int) {
+	if c.Literal == "" {
+		n := len(c.Pattern.FindAllStringIndex(s, -1))
+		if n == 0 {
+			return s, 0
+		}
+		return c.Pattern.ReplaceAllString(s, c.Replacement), n
+	}
+
+	first, _ := utf8.DecodeRuneInString(c.Literal)
+	last, _ := utf8.DecodeLastRuneInString(c.Literal)
+	var b strings.Builder
+	n, prev := 0, 0
+	for _, m := range c.Pattern.FindAllStringIndex(s, -1) {
+		before, _ := utf8.DecodeLastRuneInString(s[:m[0]])
+		after, _ := utf8.DecodeRuneInString(s[m[1]:])
+		if (isWordRune(first) && m[0] > 0 && isWordRune(before)) ||
+			(isWordRune(last) && m[1] < len(s) && isWordRune(after)) {
+			continue
+		}
+		b.WriteString(s[prev:m[0]])
+		b.WriteString(c.Replacement)
+		prev = m[1]
+		n++
+	}
+	if n == 0 {
+		return s, 0
+	}
+	b.WriteString(s[prev:])
+	return b.String(), n
+}
+
+// isWordRune reports whether r is part of a word for literal matching: a
+// letter, digit or underscore, except in scripts written without spaces
+// between words, such as Chinese and Japanese, where any match counts.
+func isWordRune(r rune) bool {
+	if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
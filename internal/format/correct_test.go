@@ -0,0 +1,73 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCorrections(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		rules   int
+		wantErr string
+	}{
+		{"literal", "rn=>m\n", 1, ""},
+		{"space around arrow", "rn => m\n/(\\d)O/ => ${1}0\n", 2, ""},
+		{"comments and blank lines", "# OCR fixes\n\n  \nrn=>m\n", 1, ""},
+		{"missing arrow", "rn=>m\nrn -> m\n", 0, "2: rule must look like wrong=>right"},
+		{"empty literal", "=>m\n", 0, "1: rule must look like wrong=>right"},
+		{"unterminated regex", "# fixes\n/rn=>m\n", 0, "2: regex rule must look like /regexp/=>replacement"},
+		{"empty regex", "//=>m\n", 0, "1: empty regex"},
+		{"invalid regex", "rn=>m\n\n/(/=>m\n", 0, "3: error parsing regexp"},
+	}
+	for _, tt := range tests {
+		rules, err := ParseCorrections(strings.NewReader(tt.input))
+		if tt.wantErr != "" {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error %v, want one starting with %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(rules) != tt.rules {
+			t.Errorf("%s: %d rules, want %d", tt.name, len(rules), tt.rules)
+		}
+	}
+}
+
+func TestApplyCorrections(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		in    string
+		want  string
+		count int
+	}{
+		{"whole words only", "teh=>the", "teh tehran teh.", "the tehran the.", 2},
+		{"not inside a word", "rn=>m", "modern rn", "modern m", 1},
+		{"underscore and digits join words", "cat=>dog", "cat_1 2cat cat", "cat_1 2cat dog", 1},
+		{"punctuation at the edge", "e.g=>e.g.", "see e.g here", "see e.g. here", 1},
+		{"Han inside text", "己经=>已经", "他己经来了", "他已经来了", 1},
+		{"ASCII end of a mixed literal", "OCR识别=>OCR 识别", "用OCR识别, xOCR识别", "用OCR 识别, xOCR识别", 1},
+		{"Han literal next to letters", "己=>已", "a己b", "a已b", 1},
+		{"regex with group", `/(\d)O/=>${1}0`, "1O 2O AO", "10 20 AO", 2},
+		{"regex with $1", `/(\w+)@@/ => $1@`, "user@@example", "user@example", 1},
+		{"applied in order", "teh=>the\nthe=>a", "teh cat", "a cat", 2},
+		{"order matters", "the=>a\nteh=>the", "teh cat", "the cat", 1},
+		{"no match", "xyz=>abc", "nothing here", "nothing here", 0},
+	}
+	for _, tt := range tests {
+		rules, err := ParseCorrections(strings.NewReader(tt.rules))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got, n := ApplyCorrections(tt.in, rules)
+		if got != tt.want || n != tt.count {
+			t.Errorf("%s: ApplyCorrections(%q) = %q, %d; want %q, %d", tt.name, tt.in, got, n, tt.want, tt.count)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package format
+
+import (
+	"strings"
+)
+
+// NormalizePage reduces page markdown to its lowercased words separated by
+// single spaces, so pages that differ only in case or whitespace compare
+// equal.
+func NormalizePage(markdown string) string {
+	return strings.Join(strings.Fields(strings.ToLower(markdown)), " ")
+}
+
+// shingleSize is the number of consecutive words compared as a unit by
+// PageSimilarity.
+const shingleSize = 3
+
+// PageSimilarity returns how alike two pages are, from 0 (nothing in
+// common) to 1 (the same text): the Dice coefficient of their multisets of
+// shingles, runs of shingleSize consecutive words after NormalizePage,
+// 2*common / (shingles in a + shingles in b). Comparing word runs rather
+// than single words makes the score sensitive to word order, so a page
+// holding the same words in a different order is not taken for a copy.
+func PageSimilarity(a, b string) float64 {
+	shinglesA := shingles(strings.Fields(NormalizePage(a)))
+	shinglesB := shingles(strings.Fields(NormalizePage(b)))
+	if len(shinglesA) == 0 && len(shinglesB) == 0 {
+		return 1
+	}
+
+	counts := make(map[string]int, len(shinglesA))
+	for _, s := range shinglesA {
+		counts[s]++
+	}
+	common := 0
+	for _, s := range shinglesB {
+		if counts[s] > 0 {
+			counts[s]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(shinglesA)+len(shinglesB))
+}
+
+// shingles returns the runs of shingleSize consecutive words, or the words
+// as a single run when there are fewer.
+func shingles(words []string) []string {
+	if len(words) == 0 {
+		return nil
+	}
+	if len(words) < shingleSize {
+		return []string{strings.Join(words, " ")}
+	}
+	runs := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		runs = append(runs, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return runs
+}
+
+// Duplicate is a page found to repeat an earlier one.
+type Duplicate struct {
+	// Index and Of are positions in the pages passed to DuplicatePages:
+	// the dropped page and the earlier page it repeats.
+	Index, Of int
+	// Similarity is 1 for exact duplicates.
+	Similarity float64
+}
+
+// DuplicatePages finds pages that repeat an earlier kept page: exactly
+// after NormalizePage, or, when threshold is above 0, with a PageSimilarity
+// of at least threshold. Blank pages are never reported. Duplicates are
+// returned in page order.
+func DuplicatePages(pages []string, threshold float64) []Duplicate {
+	var duplicates []Duplicate
+	var kept []int
+	normalized := make([]string, len(pages))
+	for i, page := range pages {
+		normalized[i] = NormalizePage(page)
+	}
+
+	for i := range pages {
+		if normalized[i] == "" {
+			continue
+		}
+		duplicate := -1
+		similarity := 0.0
+		for _, k := range kept {
+			if normalized[i] == normalized[k] {
+				duplicate, similarity = k, 1
+				break
+			}
+			if threshold > 0 {
+				if s := PageSimilarity(normalized[i], normalized[k]); s >= threshold && s > similarity {
+					duplicate, similarity = k, s
+				}
+			}
+		}
+		if duplicate < 0 {
+			kept = append(kept, i)
+			continue
+		}
+		duplicates = append(duplicates, Duplicate{Index: i, Of: duplicate, Similarity: similarity})
+	}
+	return duplicates
+}
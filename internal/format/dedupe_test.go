@@ -0,0 +1,65 @@
+package format
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPageSimilarity(t *testing.T) {
+	page := "the quick brown fox jumps over the lazy dog near the river bank"
+
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+	}{
+		{"identical", page, page, 1, 1},
+		{"case and whitespace", page, "The  quick brown\nfox jumps over the lazy dog near the river bank", 1, 1},
+		{"one word changed", page, "the quick brown fox jumps over the lazy cat near the river bank", 0.7, 0.9},
+		{"same words reordered", page, "bank river the near dog lazy the over jumps fox brown quick the", 0, 0},
+		{"disjoint", page, "lorem ipsum dolor sit amet", 0, 0},
+		{"short pages", "page 3", "Page 3", 1, 1},
+		{"short pages reordered", "3 page", "page 3", 0, 0},
+		{"both empty", "", " \n", 1, 1},
+		{"one empty", page, "", 0, 0},
+	}
+	for _, tt := range tests {
+		got := PageSimilarity(tt.a, tt.b)
+		if got < tt.min || got > tt.max {
+			t.Errorf("%s: PageSimilarity() = %.3f, want between %.2f and %.2f", tt.name, got, tt.min, tt.max)
+		}
+		if back := PageSimilarity(tt.b, tt.a); back != got {
+			t.Errorf("%s: PageSimilarity is not symmetric: %.3f and %.3f", tt.name, got, back)
+		}
+	}
+}
+
+func TestDuplicatePages(t *testing.T) {
+	page := "chapter one it was a bright cold day in april and the clocks were striking thirteen"
+	nearly := "chapter one it was a bright cold day in april and the clocks were striking twelve"
+	reordered := "thirteen striking were clocks the and april in day cold bright a was it one chapter"
+
+	tests := []struct {
+		name      string
+		pages     []string
+		threshold float64
+		want      []int // Index, Of pairs
+	}{
+		{"no duplicates", []string{page, "another page entirely"}, 0, nil},
+		{"exact after normalizing", []string{page, "  CHAPTER one " + page[12:]}, 0, []int{1, 0}},
+		{"near duplicate without threshold", []string{page, nearly}, 0, nil},
+		{"near duplicate with threshold", []string{page, nearly}, 0.8, []int{1, 0}},
+		{"reordered is kept", []string{page, reordered}, 0.5, nil},
+		{"blank pages are kept", []string{"", page, " ", page}, 0, []int{3, 1}},
+		{"compared with kept pages only", []string{page, page, page}, 0, []int{1, 0, 2, 0}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, d := range DuplicatePages(tt.pages, tt.threshold) {
+			got = append(got, d.Index, d.Of)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: DuplicatePages() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
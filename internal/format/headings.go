@@ -0,0 +1,105 @@
+// Package format provides post-processing of OCR markdown output.
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Heading is a markdown heading found in OCR output.
+type Heading struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Page   int    `json:"page"`
+	Anchor string `json:"anchor"`
+}
+
+var atxHeading = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)[ \t]*#*[ \t]*$`)
+
+// ExtractHeadings returns the ATX headings of each page in document order.
+// Page numbers are 1-based indexes into pages. Anchors follow GitHub's
+// slug rules and are unique across the whole document.
+func ExtractHeadings(pages []string) []Heading {
+	var headings []Heading
+	slugs := make(map[string]int)
+
+	for i, page := range pages {
+		inFence := false
+		for _, line := range strings.Split(page, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+				inFence = !inFence
+				continue
+			}
+			if inFence {
+				continue
+			}
+
+			match := atxHeading.FindStringSubmatch(line)
+			if match == nil || match[2] == "" {
+				continue
+			}
+
+			text := match[2]
+			headings = append(headings, Heading{
+				Level:  len(match[1]),
+				Text:   text,
+				Page:   i + 1,
+				Anchor: uniqueSlug(Slugify(text), slugs),
+			})
+		}
+	}
+
+	return headings
+}
+
+// Slugify converts heading text to a GitHub-style anchor: lowercase, with
+// punctuation removed and spaces replaced by hyphens.
+func Slugify(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// uniqueSlug disambiguates repeated slugs with a numeric suffix.
+func uniqueSlug(slug string, seen map[string]int) string {
+	count := seen[slug]
+	seen[slug] = count + 1
+	if count == 0 {
+		return slug
+	}
+	return fmt.Sprintf("%s-%d", slug, count)
+}
+
+// TableOfContents renders headings as a nested markdown list linking to
+// their anchors, annotated with page numbers. It returns "" when there are
+// no headings.
+func TableOfContents(headings []Heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	minLevel := headings[0].Level
+	for _, h := range headings {
+		if h.Level < minLevel {
+			minLevel = h.Level
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("## Contents\n\n")
+	for _, h := range headings {
+		indent := strings.Repeat("  ", h.Level-minLevel)
+		fmt.Fprintf(&b, "%s- [%s](#%s) (p. %d)\n", indent, h.Text, h.Anchor, h.Page)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,94 @@
+package format
+
+import (
+	"sort"
+	"strings"
+)
+
+// Section is a part of a document starting at a heading.
+type Section struct {
+	// Title is the heading text, or "" for content before the first heading.
+	Title string
+	// Slug is a file-name-safe form of Title, unique within the document.
+	Slug     string
+	Markdown string
+}
+
+// PreambleSlug names the section holding content before the first heading.
+const PreambleSlug = "preamble"
+
+// SplitSections splits markdown at ATX headings of the given level or
+// higher (e.g. level 2 splits at both # and ## headings). Headings inside
+// fenced code blocks are ignored. Content before the first heading becomes
+// a section with an empty title, omitted when blank.
+func SplitSections(markdown string, level int) []Section {
+	var sections []Section
+	slugs := map[string]int{PreambleSlug: 1}
+	current := Section{Slug: PreambleSlug}
+	var lines []string
+
+	flush := func() {
+		current.Markdown = strings.TrimSpace(strings.Join(lines, "\n"))
+		if current.Title != "" || current.Markdown != "" {
+			sections = append(sections, current)
+		}
+	}
+
+	inFence := false
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+
+		if !inFence {
+			if match := atxHeading.FindStringSubmatch(line); match != nil && match[2] != "" && len(match[1]) <= level {
+				flush()
+				slug := Slugify(match[2])
+				if slug == "" {
+					slug = "section"
+				}
+				current = Section{Title: match[2], Slug: uniqueSlug(slug, slugs)}
+				lines = nil
+			}
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return sections
+}
+
+// ImageRefs returns the keys of images that are referenced in markdown, in
+// sorted order.
+func ImageRefs(markdown string, images map[string]string) []string {
+	var refs []string
+	for name := range images {
+		if strings.Contains(markdown, name) {
+			refs = append(refs, name)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// RewriteImageRefs replaces references to the given images in markdown,
+// both markdown links and HTML src attributes, with baseURL followed by the
+// image's relative path.
+func RewriteImageRefs(markdown string, images map[string]string, baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	var pairs []string
+	for _, name := range ImageRefs(markdown, images) {
+		url := baseURL + "/" + strings.TrimPrefix(name, "./")
+		pairs = append(pairs,
+			"]("+name+")", "]("+url+")",
+			"]("+name+" ", "]("+url+" ",
+			`src="`+name+`"`, `src="`+url+`"`,
+			`src='`+name+`'`, `src='`+url+`'`,
+		)
+	}
+	if len(pairs) == 0 {
+		return markdown
+	}
+	return strings.NewReplacer(pairs...).Replace(markdown)
+}
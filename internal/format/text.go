@@ -0,0 +1,25 @@
+package format
+
+import (
+	"regexp"
+	"unicode"
+)
+
+var (
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	htmlTag       = regexp.MustCompile(`<[^>]+>`)
+)
+
+// TextLength returns the number of characters of text in markdown, not
+// counting whitespace, images or HTML tags, such as the <div> and <img>
+// the server wraps figures in.
+func TextLength(markdown string) int {
+	text := htmlTag.ReplaceAllString(markdownImage.ReplaceAllString(markdown, ""), "")
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,108 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// protectedSpan matches inline code, links, images and autolinks, which
+	// must not be broken across lines.
+	protectedSpan = regexp.MustCompile("`[^`]*`|!?\\[[^\\]]*\\]\\([^)]*\\)|<[^>]*>")
+	// blockMarker matches words that would start a list, heading or quote if
+	// they began a line.
+	blockMarker = regexp.MustCompile(`^(?:[-*+>]|#{1,6}|\d+[.)])$`)
+	// linePrefix matches indentation, blockquote markers and an optional
+	// list marker at the start of a line.
+	linePrefix = regexp.MustCompile(`^([ \t]*(?:>[ \t]?)*)(?:([-*+]|\d+[.)])([ \t]+))?`)
+)
+
+// nbsp stands in for spaces inside protected spans while splitting words.
+const nbsp = "\x00"
+
+// Wrap hard-wraps prose lines longer than width runes, breaking only at
+// spaces. Fenced and indented code, tables, HTML blocks and headings are
+// left as they are, and links, images, inline code and URLs are never
+// split. List items and blockquotes keep their indentation and markers on
+// continuation lines. A width of 0 or less disables wrapping.
+func Wrap(markdown string, width int) string {
+	if width <= 0 {
+		return markdown
+	}
+
+	lines := strings.Split(markdown, "\n")
+	var out []string
+	inFence := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			out = append(out, line)
+			continue
+		}
+		if inFence || !isProse(line) || utf8.RuneCountInString(line) <= width {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, wrapLine(line, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// isProse reports whether a line is ordinary text that may be wrapped.
+func isProse(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "":
+		return false
+	case strings.HasPrefix(line, "    "), strings.HasPrefix(line, "\t"):
+		return false // indented code
+	case strings.HasPrefix(trimmed, "|"), strings.HasPrefix(trimmed, "<"):
+		return false // tables and HTML
+	case atxHeading.MatchString(line):
+		return false
+	}
+	return true
+}
+
+// wrapLine breaks a single line into lines of at most width runes where
+// possible, repeating its blockquote prefix and indenting past any list
+// marker on continuation lines.
+func wrapLine(line string, width int) []string {
+	match := linePrefix.FindStringSubmatch(line)
+	first := match[0]
+	rest := match[1] + strings.Repeat(" ", utf8.RuneCountInString(match[2]+match[3]))
+	body := line[len(first):]
+
+	hardBreak := strings.HasSuffix(body, "  ")
+	body = protectedSpan.ReplaceAllStringFunc(body, func(span string) string {
+		return strings.ReplaceAll(span, " ", nbsp)
+	})
+
+	var lines []string
+	current := first
+	empty := true
+	for _, word := range strings.Fields(body) {
+		word = strings.ReplaceAll(word, nbsp, " ")
+		if !empty && utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width && !startsBlock(word) {
+			lines = append(lines, current)
+			current, empty = rest, true
+		}
+		if !empty {
+			current += " "
+		}
+		current += word
+		empty = false
+	}
+	if hardBreak {
+		current += "  "
+	}
+	return append(lines, current)
+}
+
+// startsBlock reports whether a word would change the meaning of a
+// continuation line it started, such as "-" or "1.".
+func startsBlock(word string) bool {
+	return blockMarker.MatchString(word)
+}
@@ -0,0 +1,142 @@
+// Package i18n translates user-facing messages. Each message has a stable
+// ID and its English text in the code, which is used when the locale has
+// no translation. Other locales are JSON catalogs mapping IDs to
+// fmt-style formats, locales/<locale>.json, embedded in the binary: adding
+// a locale only takes a new catalog.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+)
+
+// LangEnvVar selects the locale, e.g. zh-CN, ahead of the POSIX LC_ALL,
+// LC_MESSAGES and LANG variables.
+const LangEnvVar = "PADDLEOCR_LANG"
+
+//go:embed locales/*.json
+var catalogs embed.FS
+
+var (
+	once    sync.Once
+	locale  string
+	catalog map[string]string
+)
+
+// T returns the message with the given ID in the current locale, formatted
+// with args as by fmt.Sprintf. english is the message's English format,
+// used when the locale has no translation for id or its translation does
+// not take the same number of arguments.
+func T(id, english string, args ...interface{}) string {
+	once.Do(load)
+	format := english
+	if translated, ok := catalog[id]; ok && verbs(translated) == verbs(english) {
+		format = translated
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Error is an error whose message is translated like T. It keeps the
+// message ID, so machine-readable output can identify the error whatever
+// the locale.
+type Error struct {
+	ID      string
+	english string
+	args    []interface{}
+}
+
+// Errorf returns an error with the message T(id, english, args...).
+func Errorf(id, english string, args ...interface{}) error {
+	return &Error{ID: id, english: english, args: args}
+}
+
+// Error returns the message in the current locale.
+func (e *Error) Error() string {
+	return T(e.ID, e.english, e.args...)
+}
+
+// ErrorID returns the message ID of err, or of the first *Error it wraps,
+// or "" when it has none.
+func ErrorID(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.ID
+	}
+	return ""
+}
+
+// Locale returns the locale messages are translated to, or "en" when they
+// are in English.
+func Locale() string {
+	once.Do(load)
+	return locale
+}
+
+// load finds the catalog for the locale named by the environment.
+func load() {
+	locale = "en"
+	name := requestedLocale()
+	if name == "" {
+		return
+	}
+	entries, err := catalogs.ReadDir("locales")
+	if err != nil {
+		return
+	}
+	var match string
+	for _, entry := range entries {
+		candidate := strings.TrimSuffix(entry.Name(), ".json")
+		switch {
+		case strings.EqualFold(candidate, name):
+			match = candidate
+		case match == "" && strings.EqualFold(language(candidate), language(name)):
+			// Another region of the language, e.g. zh-CN for zh or zh-SG.
+			match = candidate
+		}
+	}
+	if match == "" {
+		return
+	}
+	data, err := catalogs.ReadFile(path.Join("locales", match+".json"))
+	if err != nil || json.Unmarshal(data, &catalog) != nil {
+		catalog = nil
+		return
+	}
+	locale = match
+}
+
+// requestedLocale returns the locale from PADDLEOCR_LANG or the POSIX
+// variables, as language-REGION without encoding, e.g. zh-CN for
+// zh_CN.UTF-8, or "" for none, C or POSIX.
+func requestedLocale() string {
+	for _, name := range []string{LangEnvVar, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		value, _, _ = strings.Cut(value, ".")
+		value, _, _ = strings.Cut(value, "@")
+		if value == "C" || value == "POSIX" {
+			return ""
+		}
+		return strings.ReplaceAll(value, "_", "-")
+	}
+	return ""
+}
+
+// language returns the language part of a locale, e.g. zh for zh-CN.
+func language(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return lang
+}
+
+// verbs counts the formatting verbs in a format, not counting %%.
+func verbs(format string) int {
+	return strings.Count(format, "%") - 2*strings.Count(format, "%%")
+}
@@ -0,0 +1,32 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorKeepsID(t *testing.T) {
+	t.Setenv(LangEnvVar, "zh-CN")
+
+	err := Errorf("ocr.file_not_found", "File not found: %s", "a.png")
+	if got, want := err.Error(), "文件不存在：a.png"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"direct", err, "ocr.file_not_found"},
+		{"wrapped", fmt.Errorf("scan.png: %w", err), "ocr.file_not_found"},
+		{"plain", errors.New("boom"), ""},
+		{"nil", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := ErrorID(tt.err); got != tt.want {
+			t.Errorf("%s: ErrorID() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// Rect is a rectangle of an image in pixels, from its top-left corner.
+type Rect struct {
+	X, Y, Width, Height int
+}
+
+// String formats r as ParseRect reads it.
+func (r Rect) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height)
+}
+
+// ParseRect parses a rectangle given as "X,Y,W,H".
+func ParseRect(s string) (Rect, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return Rect{}, fmt.Errorf("expected X,Y,W,H, got %q", s)
+	}
+	var values [4]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 0 {
+			return Rect{}, fmt.Errorf("expected X,Y,W,H as non-negative integers, got %q", s)
+		}
+		values[i] = n
+	}
+	r := Rect{X: values[0], Y: values[1], Width: values[2], Height: values[3]}
+	if r.Width == 0 || r.Height == 0 {
+		return Rect{}, fmt.Errorf("width and height must be positive, got %q", s)
+	}
+	return r, nil
+}
+
+// Crop cuts r out of an image and re-encodes it as PNG, which is lossless
+// so the text is not degraded further. r is taken in the image as
+// displayed: a JPEG is first turned upright by its EXIF orientation, which
+// the PNG does not carry. It fails when r does not lie within the image.
+func Crop(data []byte, r Rect) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %v", err)
+	}
+	src = orient(src, Orientation(data))
+
+	bounds := src.Bounds()
+	rect := image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height).Add(bounds.Min)
+	if !rect.In(bounds) {
+		return nil, fmt.Errorf("crop %s is outside the %dx%d image", r, bounds.Dx(), bounds.Dy())
+	}
+
+	var cropped image.Image
+	if sub, ok := src.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		cropped = sub.SubImage(rect)
+	} else {
+		dst := image.NewRGBA(image.Rect(0, 0, r.Width, r.Height))
+		draw.Copy(dst, image.Point{}, src, rect, draw.Src, nil)
+		cropped = dst
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, cropped); err != nil {
+		return nil, fmt.Errorf("cannot encode PNG: %v", err)
+	}
+	return buf.Bytes(), nil
+}
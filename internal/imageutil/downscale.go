@@ -0,0 +1,87 @@
+// Package imageutil provides image helpers used before uploading documents.
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+
+	// Register the decoders for the image formats accepted for OCR.
+	_ "image/gif"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// DefaultJPEGQuality is the JPEG quality used when none is given.
+const DefaultJPEGQuality = 85
+
+// Result describes a downscaled image.
+type Result struct {
+	Data          []byte
+	Width, Height int
+	// OrigWidth and OrigHeight are the upright dimensions of the input
+	// image.
+	OrigWidth, OrigHeight int
+}
+
+// Downscale shrinks an image so that neither side exceeds maxDimension,
+// preserving its aspect ratio, and re-encodes it as JPEG at quality.
+// Transparent areas are flattened onto white. The EXIF orientation is
+// applied, since the re-encoded image carries no EXIF data, so dimensions
+// are those of the upright image. It returns nil when the image already
+// fits, so the original bytes can be sent unchanged.
+func Downscale(data []byte, maxDimension, quality int) (*Result, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read image: %v", err)
+	}
+	orientation := Orientation(data)
+	origWidth, origHeight := cfg.Width, cfg.Height
+	if swapsSides(orientation) {
+		origWidth, origHeight = origHeight, origWidth
+	}
+	if origWidth <= maxDimension && origHeight <= maxDimension {
+		return nil, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %v", err)
+	}
+
+	// Scale the stored pixels, then orient the smaller result.
+	width, height := fit(origWidth, origHeight, maxDimension)
+	scaledWidth, scaledHeight := width, height
+	if swapsSides(orientation) {
+		scaledWidth, scaledHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, orient(dst, orientation), &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("cannot encode JPEG: %v", err)
+	}
+	return &Result{
+		Data:       buf.Bytes(),
+		Width:      width,
+		Height:     height,
+		OrigWidth:  origWidth,
+		OrigHeight: origHeight,
+	}, nil
+}
+
+// fit scales width and height down so the longer side is maxDimension.
+func fit(width, height, maxDimension int) (int, int) {
+	if width >= height {
+		return maxDimension, max(1, height*maxDimension/width)
+	}
+	return max(1, width*maxDimension/height), maxDimension
+}
@@ -0,0 +1,134 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientationTag is the EXIF tag holding the orientation.
+const exifOrientationTag = 0x0112
+
+// Orientation returns the EXIF orientation (1-8) of a JPEG image: how its
+// stored pixels must be rotated or mirrored to display upright. It returns
+// 1, the identity, for other formats and when the tag is missing or
+// malformed.
+func Orientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xD8 || (marker >= 0xD0 && marker <= 0xD7) || marker == 0xFF {
+			i++
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			// Image data starts; metadata segments come before it.
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of a TIFF
+// structure, as embedded in an EXIF segment.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		// A SHORT value is stored inline at the start of the value field.
+		if value := int(order.Uint16(tiff[entry+8:])); value >= 1 && value <= 8 {
+			return value
+		}
+		return 1
+	}
+	return 1
+}
+
+// swapsSides reports whether an orientation rotates the image by 90
+// degrees, so its displayed width is its stored height.
+func swapsSides(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
+
+// orient returns src rotated and mirrored as its EXIF orientation requires
+// to display upright. Orientation 1 returns src unchanged.
+func orient(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if swapsSides(orientation) {
+		dw, dh = h, w
+	}
+
+	// at maps a pixel of the upright image to the stored pixel shown there.
+	var at func(x, y int) (int, int)
+	switch orientation {
+	case 2: // mirrored horizontally
+		at = func(x, y int) (int, int) { return w - 1 - x, y }
+	case 3: // rotated 180
+		at = func(x, y int) (int, int) { return w - 1 - x, h - 1 - y }
+	case 4: // mirrored vertically
+		at = func(x, y int) (int, int) { return x, h - 1 - y }
+	case 5: // transposed
+		at = func(x, y int) (int, int) { return y, x }
+	case 6: // needs a 90 degree clockwise turn
+		at = func(x, y int) (int, int) { return y, h - 1 - x }
+	case 7: // transversed
+		at = func(x, y int) (int, int) { return w - 1 - y, h - 1 - x }
+	case 8: // needs a 90 degree counter-clockwise turn
+		at = func(x, y int) (int, int) { return w - 1 - y, x }
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			sx, sy := at(x, y)
+			dst.Set(x, y, src.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return dst
+}
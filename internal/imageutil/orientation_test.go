@@ -0,0 +1,188 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// exifSegment returns an APP1 segment holding only an orientation tag.
+func exifSegment(order binary.ByteOrder, orientation int) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], uint16(orientation))
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withEXIF inserts an EXIF orientation right after the JPEG start marker.
+func withEXIF(jpg []byte, order binary.ByteOrder, orientation int) []byte {
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, exifSegment(order, orientation)...)
+	return append(out, jpg[2:]...)
+}
+
+// halves returns a w x h JPEG whose left half is red and right half blue.
+func halves(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOrientation(t *testing.T) {
+	jpg := halves(t, 4, 2)
+	var pngData bytes.Buffer
+	png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no EXIF", jpg, 1},
+		{"little endian", withEXIF(jpg, binary.LittleEndian, 6), 6},
+		{"big endian", withEXIF(jpg, binary.BigEndian, 8), 8},
+		{"out of range", withEXIF(jpg, binary.BigEndian, 9), 1},
+		{"PNG", pngData.Bytes(), 1},
+		{"truncated", withEXIF(jpg, binary.LittleEndian, 3)[:20], 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		if got := Orientation(tt.data); got != tt.want {
+			t.Errorf("%s: Orientation() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// isRed reports whether c is closer to red than to blue.
+func isRed(c color.Color) bool {
+	r, _, b, _ := c.RGBA()
+	return r > b
+}
+
+func TestDownscaleAppliesOrientation(t *testing.T) {
+	// Stored 40x20 with red on the left; orientation 6 displays it turned
+	// clockwise, 20x40 with red on top.
+	data := withEXIF(halves(t, 40, 20), binary.BigEndian, 6)
+
+	result, err := Downscale(data, 10, 95)
+	if err != nil {
+		t.Fatalf("Downscale: %v", err)
+	}
+	if result.OrigWidth != 20 || result.OrigHeight != 40 || result.Width != 5 || result.Height != 10 {
+		t.Errorf("dimensions %dx%d -> %dx%d, want 20x40 -> 5x10",
+			result.OrigWidth, result.OrigHeight, result.Width, result.Height)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(result.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 5 || b.Dy() != 10 {
+		t.Fatalf("encoded image is %dx%d, want 5x10", b.Dx(), b.Dy())
+	}
+	if !isRed(img.At(2, 1)) || isRed(img.At(2, 8)) {
+		t.Error("image is not upright: want red on top and blue at the bottom")
+	}
+
+	// An upright image that already fits once turned is left alone.
+	if result, err := Downscale(withEXIF(halves(t, 40, 20), binary.BigEndian, 6), 40, 95); err != nil || result != nil {
+		t.Errorf("Downscale of a fitting image = %v, %v, want nil", result, err)
+	}
+}
+
+func TestOrientAllOrientations(t *testing.T) {
+	// A 3x2 image with one marked pixel in the stored top-left corner.
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, color.White)
+
+	// Where the marked pixel ends up in the upright image.
+	tests := []struct {
+		orientation int
+		w, h        int
+		x, y        int
+	}{
+		{1, 3, 2, 0, 0},
+		{2, 3, 2, 2, 0},
+		{3, 3, 2, 2, 1},
+		{4, 3, 2, 0, 1},
+		{5, 2, 3, 0, 0},
+		{6, 2, 3, 1, 0},
+		{7, 2, 3, 1, 2},
+		{8, 2, 3, 0, 2},
+	}
+	for _, tt := range tests {
+		img := orient(src, tt.orientation)
+		if b := img.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("orientation %d: size %dx%d, want %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		if r, _, _, _ := img.At(tt.x, tt.y).RGBA(); r == 0 {
+			t.Errorf("orientation %d: marked pixel not at (%d,%d)", tt.orientation, tt.x, tt.y)
+		}
+	}
+}
+
+func TestCropAppliesOrientation(t *testing.T) {
+	// Stored 40x20 with red on the left; displayed 20x40 with red on top.
+	data := withEXIF(halves(t, 40, 20), binary.BigEndian, 6)
+
+	tests := []struct {
+		rect Rect
+		red  bool
+	}{
+		{Rect{X: 0, Y: 0, Width: 20, Height: 16}, true},
+		{Rect{X: 0, Y: 24, Width: 20, Height: 16}, false},
+	}
+	for _, tt := range tests {
+		out, err := Crop(data, tt.rect)
+		if err != nil {
+			t.Fatalf("Crop(%s): %v", tt.rect, err)
+		}
+		img, err := png.Decode(bytes.NewReader(out))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.rect.Width || b.Dy() != tt.rect.Height {
+			t.Errorf("Crop(%s) is %dx%d", tt.rect, b.Dx(), b.Dy())
+		}
+		if got := isRed(img.At(img.Bounds().Min.X+10, img.Bounds().Min.Y+8)); got != tt.red {
+			t.Errorf("Crop(%s): red = %v, want %v", tt.rect, got, tt.red)
+		}
+	}
+
+	// The stored width is not the displayed one.
+	if _, err := Crop(data, Rect{X: 0, Y: 0, Width: 40, Height: 20}); err == nil {
+		t.Error("Crop accepted a rectangle wider than the upright image")
+	}
+}
@@ -0,0 +1,101 @@
+// Package jsonschema generates JSON Schema documents from Go types, so
+// published schemas stay in sync with the structs that produce the JSON.
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema object.
+type Schema = map[string]interface{}
+
+// Generator builds schemas for Go types. Named struct types are emitted once
+// under $defs and referenced elsewhere.
+type Generator struct {
+	defs Schema
+}
+
+// NewGenerator returns an empty generator.
+func NewGenerator() *Generator {
+	return &Generator{defs: Schema{}}
+}
+
+// Defs returns the struct definitions collected so far.
+func (g *Generator) Defs() Schema {
+	return g.defs
+}
+
+// Ref returns a $ref to a named definition.
+func Ref(name string) Schema {
+	return Schema{"$ref": "#/$defs/" + name}
+}
+
+// Type returns the schema for t, registering struct definitions as needed.
+func (g *Generator) Type(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": g.Type(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.Type(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = Schema{} // placeholder for recursive types
+			g.defs[t.Name()] = g.object(t)
+		}
+		return Ref(t.Name())
+	default:
+		return Schema{}
+	}
+}
+
+// object describes a struct's JSON fields. Fields without omitempty are
+// required.
+func (g *Generator) object(t reflect.Type) Schema {
+	properties := Schema{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.Type(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,93 @@
+// Package lb spreads requests across server replicas.
+package lb
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultUnhealthyWindow is how long a failed server is skipped by default.
+const DefaultUnhealthyWindow = 30 * time.Second
+
+// ServerPool hands out servers in round-robin order, skipping servers
+// marked unhealthy until their window expires. It is safe for concurrent
+// use.
+type ServerPool struct {
+	mu        sync.Mutex
+	servers   []string
+	next      int
+	window    time.Duration
+	unhealthy map[string]time.Time
+	now       func() time.Time
+}
+
+// NewServerPool returns a pool over servers. A non-positive window uses
+// DefaultUnhealthyWindow.
+func NewServerPool(servers []string, window time.Duration) *ServerPool {
+	if window <= 0 {
+		window = DefaultUnhealthyWindow
+	}
+	return &ServerPool{
+		servers:   servers,
+		window:    window,
+		unhealthy: make(map[string]time.Time),
+		now:       time.Now,
+	}
+}
+
+// Servers returns all servers in the pool, healthy or not.
+func (p *ServerPool) Servers() []string {
+	return p.servers
+}
+
+// Next returns the next healthy server in round-robin order. When every
+// server is unhealthy, the next one is returned regardless. It returns ""
+// for an empty pool.
+func (p *ServerPool) Next() string {
+	order := p.Rotation()
+	if len(order) == 0 {
+		return ""
+	}
+	return order[0]
+}
+
+// Rotation advances the round robin and returns the servers to try for one
+// request: the next healthy server first, then the other healthy servers
+// for failover, then the unhealthy ones as a last resort.
+func (p *ServerPool) Rotation() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.servers)
+	if n == 0 {
+		return nil
+	}
+
+	now := p.now()
+	var healthy, unhealthy []string
+	for i := 0; i < n; i++ {
+		server := p.servers[(p.next+i)%n]
+		if until, ok := p.unhealthy[server]; ok && now.Before(until) {
+			unhealthy = append(unhealthy, server)
+			continue
+		}
+		delete(p.unhealthy, server)
+		healthy = append(healthy, server)
+	}
+
+	order := append(healthy, unhealthy...)
+	for i, server := range p.servers {
+		if server == order[0] {
+			p.next = (i + 1) % n
+			break
+		}
+	}
+	return order
+}
+
+// MarkUnhealthy excludes url from rotation for the pool's window.
+func (p *ServerPool) MarkUnhealthy(url string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.unhealthy[url] = p.now().Add(p.window)
+}
@@ -0,0 +1,24 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewFDWriter returns a writer for the already open file descriptor fd, such
+// as one set up by a shell redirection like 3>progress.log. It falls back to
+// stderr when fd is not open.
+func NewFDWriter(fd int) io.Writer {
+	if fd < 0 {
+		return os.Stderr
+	}
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return os.Stderr
+	}
+	if _, err := f.Stat(); err != nil {
+		return os.Stderr
+	}
+	return f
+}
@@ -0,0 +1,241 @@
+// Package log provides a minimal leveled logger for diagnostic and progress
+// messages. It is not built on log/slog: slog's JSON handler is used only
+// to encode records in FormatJSON, while text output, levels, the progress
+// writer and the log file are handled here.
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+)
+
+// Level is a message severity. Messages below a logger's level are dropped.
+type Level int
+
+// Levels, from most to least verbose.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// levelNames maps levels to their names, as accepted by ParseLevel.
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// String returns the level's name.
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// LevelNames returns the level names accepted by ParseLevel.
+func LevelNames() []string {
+	return append([]string(nil), levelNames...)
+}
+
+// ParseLevel parses a level name: debug, info, warn or error.
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid log level %q (valid: %s)", name, strings.Join(levelNames, ", "))
+}
+
+// Output formats, as accepted by SetFormat.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// slogLevels maps levels to their log/slog equivalents.
+var slogLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// Logger writes messages at or above its level to a writer, one per line.
+// Debug messages are prefixed with "[debug] ", warnings with "Warning: "
+// and errors with "Error: ", the latter two translated (see i18n); info
+// messages are written as is, to the progress writer when one is set. A
+// nil *Logger discards everything.
+//
+// With SetColor the prefixes are colored on the console. The file set by
+// SetFile and JSON records carry the level name instead, uncolored and in
+// English.
+type Logger struct {
+	mu       sync.Mutex
+	w        io.Writer
+	progress io.Writer
+	level    Level
+	// json, when set, replaces the text lines with JSON records.
+	json *slog.Logger
+	// file receives every message, whatever the level.
+	file io.Writer
+	// color colors the console prefixes.
+	color bool
+}
+
+// ANSI styles for colored console output, shared with the progress bar.
+const (
+	StyleDim    = "\x1b[2m"
+	StyleRed    = "\x1b[31m"
+	StyleGreen  = "\x1b[32m"
+	StyleYellow = "\x1b[33m"
+	StyleReset  = "\x1b[0m"
+)
+
+// prefix returns the console prefix of messages at level, colored with
+// color.
+func prefix(level Level, color bool) string {
+	var text, style string
+	switch level {
+	case LevelDebug:
+		text, style = "[debug] ", StyleDim
+	case LevelWarn:
+		text, style = i18n.T("log.warning", "Warning: "), StyleYellow
+	case LevelError:
+		text, style = i18n.T("log.error", "Error: "), StyleRed
+	default:
+		return ""
+	}
+	if !color {
+		return text
+	}
+	label := strings.TrimRight(text, " ")
+	return style + label + StyleReset + text[len(label):]
+}
+
+// New returns a logger writing messages at level or above to w.
+func New(w io.Writer, level Level) *Logger {
+	return &Logger{w: w, level: level}
+}
+
+// SetLevel changes the minimum level written.
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// Level returns the minimum level written to the console.
+func (l *Logger) Level() Level {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// SetOutput changes the writer for console messages.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w = w
+}
+
+// SetProgressWriter sends info messages to w instead of the logger's
+// writer. A nil w restores the default.
+func (l *Logger) SetProgressWriter(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.progress = w
+}
+
+// SetFormat selects the output format: FormatText for the prefixed lines
+// described on Logger, or FormatJSON for one JSON object per message with
+// "time", "level" and "msg" keys, for log ingestion. JSON records all go to
+// the logger's writer, including info messages.
+func (l *Logger) SetFormat(format string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch strings.ToLower(format) {
+	case FormatText:
+		l.json = nil
+	case FormatJSON:
+		l.json = slog.New(slog.NewJSONHandler(l.w, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	default:
+		return fmt.Errorf("invalid log format %q (valid: %s, %s)", format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+// SetColor turns colored prefixes on the console on or off.
+func (l *Logger) SetColor(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.color = enabled
+}
+
+// SetFile copies every message to w as well, at any level, prefixed with
+// a timestamp and the level name, so a run can be inspected afterwards
+// whatever was shown on the console. A nil w stops the copying.
+func (l *Logger) SetFile(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.file = w
+}
+
+// Enabled reports whether messages at level are written, to the console
+// or to the file set by SetFile.
+func (l *Logger) Enabled(level Level) bool {
+	if l == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return level >= l.level || l.file != nil
+}
+
+// Debugf writes a diagnostic message.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, format, args...)
+}
+
+// Infof writes a progress message.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, format, args...)
+}
+
+// Warnf writes a warning.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, format, args...)
+}
+
+// Errorf writes an error.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, format, args...)
+}
+
+func (l *Logger) logf(level Level, format string, args ...interface{}) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < l.level && l.file == nil {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if l.file != nil {
+		fmt.Fprintf(l.file, "%s %-5s %s\n", time.Now().Format("2006-01-02T15:04:05.000Z07:00"), strings.ToUpper(level.String()), msg)
+	}
+	if level < l.level {
+		return
+	}
+	if l.json != nil {
+		l.json.Log(context.Background(), slogLevels[level], msg)
+		return
+	}
+	w := l.w
+	if level == LevelInfo && l.progress != nil {
+		w = l.progress
+	}
+	fmt.Fprintln(w, prefix(level, l.color)+msg)
+}
@@ -0,0 +1,54 @@
+// Package api defines the version-neutral request and response types shared
+// by the versioned PaddleOCR wire formats.
+package api
+
+// Feature names of the optional pipeline switches. They double as the
+// capability names advertised by the server.
+const (
+	FeatureDocOrientationClassify = "useDocOrientationClassify"
+	FeatureDocUnwarping           = "useDocUnwarping"
+	FeatureChartRecognition       = "useChartRecognition"
+)
+
+// Request is a layout parsing request, without the file content.
+type Request struct {
+	FileType int
+	// Features holds the optional pipeline switches keyed by feature name.
+	// Absent features are not sent.
+	Features map[string]bool
+}
+
+// Page is the markdown result for a single page.
+type Page struct {
+	Markdown string
+	Images   map[string]string
+	// Index is the 0-based page of the document the server says this
+	// result comes from, or nil when it did not say. Results need not map
+	// 1:1 to pages, for example when the server splits a page into regions.
+	Index *int
+}
+
+// Response is a decoded layout parsing response.
+type Response struct {
+	LogID     string
+	ErrorCode int
+	ErrorMsg  string
+	Pages     []Page
+}
+
+// Encoder marshals requests for one API version.
+type Encoder interface {
+	// Encode returns the request fields, excluding the file content.
+	Encode(req *Request) map[string]interface{}
+	// FileField returns the name of the field carrying the file content.
+	FileField() string
+}
+
+// Decoder unmarshals responses for one API version.
+type Decoder interface {
+	Decode(body []byte) (*Response, error)
+	// DecodeHeader returns the response without its pages, and the number
+	// of pages it lists. Their content is skipped, not allocated, so a
+	// limit on the page count can be enforced before decoding them.
+	DecodeHeader(body []byte) (*Response, int, error)
+}
@@ -0,0 +1,122 @@
+package ocr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr/api"
+)
+
+// Capability names advertised by the capabilities endpoint.
+const (
+	FeatureMultipart = "multipart"
+	FeatureGzip      = "gzip"
+)
+
+// ServerCapabilities is the result of the capabilities endpoint.
+type ServerCapabilities struct {
+	Features   []string `json:"features"`
+	APIVersion string   `json:"apiVersion,omitempty"`
+}
+
+// Has reports whether the server advertises a feature.
+func (sc *ServerCapabilities) Has(feature string) bool {
+	if sc == nil {
+		return false
+	}
+	for _, f := range sc.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// FetchCapabilities queries the capabilities endpoint. The result, including
+// a failure, is cached for the lifetime of the client. Cancelling ctx
+// abandons the request.
+func (c *Client) FetchCapabilities(ctx context.Context) (*ServerCapabilities, error) {
+	c.capabilitiesOnce.Do(func() {
+		c.capabilities, c.capabilitiesErr = c.requestCapabilities(ctx)
+	})
+	return c.capabilities, c.capabilitiesErr
+}
+
+func (c *Client) requestCapabilities(ctx context.Context) (*ServerCapabilities, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.GetEndpoint(CapabilitiesEndpoint), nil)
+	if err != nil {
+		return nil, i18n.Errorf("ocr.create_request_failed", "Failed to create request: %v", err)
+	}
+	c.setHeaders(req, c.AccessToken())
+
+	client := c.newHTTPClient(10 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, i18n.Errorf("ocr.connection_failed", "Connection failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, i18n.Errorf("ocr.http_status", "HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := readBody(resp)
+	if err != nil {
+		return nil, i18n.Errorf("ocr.read_response_failed", "Failed to read response: %v", err)
+	}
+
+	var response struct {
+		ErrorCode int                `json:"errorCode"`
+		ErrorMsg  string             `json:"errorMsg"`
+		Result    ServerCapabilities `json:"result"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, i18n.Errorf("ocr.invalid_json", "Invalid JSON response: %v", err)
+	}
+	if response.ErrorCode != 0 {
+		return nil, i18n.Errorf("ocr.server_error", "Server error: %s", response.ErrorMsg)
+	}
+
+	return &response.Result, nil
+}
+
+// SupportsFeature reports whether the server advertises a capability.
+// Servers without the capabilities endpoint support no optional features.
+func (c *Client) SupportsFeature(ctx context.Context, feature string) bool {
+	capabilities, err := c.FetchCapabilities(ctx)
+	if err != nil {
+		return false
+	}
+	return capabilities.Has(feature)
+}
+
+// supportsMultipart reports whether the server accepts multipart uploads.
+func (c *Client) supportsMultipart(ctx context.Context) bool {
+	return c.SupportsFeature(ctx, FeatureMultipart)
+}
+
+// filterFeatures removes optional features the server does not advertise, so
+// older servers are not sent fields they reject. When the capabilities are
+// unknown the request is sent unchanged.
+func (c *Client) filterFeatures(ctx context.Context, req *api.Request) {
+	capabilities, err := c.FetchCapabilities(ctx)
+	if err != nil {
+		return
+	}
+	names := make([]string, 0, len(req.Features))
+	for name := range req.Features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !capabilities.Has(name) {
+			c.debugf("Server does not support %s; omitting it from the request", name)
+			delete(req.Features, name)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package ocr
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+)
+
+// String returns "pdf" or "image".
+func (t FileType) String() string {
+	if t == FileTypePDF {
+		return "pdf"
+	}
+	return "image"
+}
+
+// CheckFile makes the local checks OCRFile runs before reading a file, so
+// obvious mistakes fail fast instead of as vague server errors: the file
+// must exist, be accessible, be a file and have content.
+func CheckFile(filePath string) error {
+	info, err := os.Stat(filePath)
+	switch {
+	case os.IsNotExist(err):
+		return i18n.Errorf("ocr.file_not_found", "File not found: %s", filePath)
+	case os.IsPermission(err):
+		return i18n.Errorf("ocr.permission_denied", "Permission denied: %s", filePath)
+	case err == nil && info.IsDir():
+		return i18n.Errorf("ocr.not_a_file", "Not a file: %s", filePath)
+	case err == nil && info.Size() == 0:
+		return i18n.Errorf("ocr.file_empty", "File is empty: %s", filePath)
+	}
+	return nil
+}
+
+// InspectFile reports what OCRFile would send for filePath without sending
+// it: the file type and the warnings about its name and content that the
+// result would carry. It fails where OCRFile would before any request.
+func InspectFile(filePath string) (FileType, []string, error) {
+	if err := CheckFile(filePath); err != nil {
+		return 0, nil, err
+	}
+	f, err := os.Open(filePath)
+	if os.IsPermission(err) {
+		return 0, nil, i18n.Errorf("ocr.permission_denied", "Permission denied: %s", filePath)
+	}
+	if err != nil {
+		return 0, nil, i18n.Errorf("ocr.read_failed", "Failed to read file: %v", err)
+	}
+	defer f.Close()
+	head := make([]byte, 8)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return 0, nil, i18n.Errorf("ocr.read_failed", "Failed to read file: %v", err)
+	}
+
+	fileType, known := detectFileType(filePath)
+	var warnings []string
+	if !known {
+		warnings = append(warnings, unknownExtensionWarning(filePath))
+	}
+	if warning := sniffWarning(head[:n], fileType); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	return fileType, warnings, nil
+}
+
+// unknownExtensionWarning is the warning for a file whose extension
+// detectFileType does not recognize.
+func unknownExtensionWarning(filePath string) string {
+	return fmt.Sprintf("unknown extension %q; sent as an image", filepath.Ext(filePath))
+}
@@ -3,22 +3,54 @@ package ocr
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/Explorer1092/paddleocr_cli/internal/config"
+	"github.com/Explorer1092/paddleocr_cli/internal/format"
+	"github.com/Explorer1092/paddleocr_cli/internal/i18n"
+	"github.com/Explorer1092/paddleocr_cli/internal/imageutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/lb"
+	"github.com/Explorer1092/paddleocr_cli/internal/log"
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr/api"
+	"github.com/Explorer1092/paddleocr_cli/internal/pdfutil"
+	"github.com/Explorer1092/paddleocr_cli/internal/ratelimit"
+	"github.com/Explorer1092/paddleocr_cli/internal/retry"
 )
 
+// DefaultUserAgent is sent when neither the options nor the config set one.
+const DefaultUserAgent = "paddleocr-cli"
+
+// DefaultConnectTimeout limits connection setup when ClientOptions does not.
+const DefaultConnectTimeout = 30 * time.Second
+
+// ErrUnauthorized is set on a result when the server rejects the access token.
+var ErrUnauthorized = errors.New("unauthorized: access token rejected")
+
 const (
 	LayoutParsingEndpoint = "/layout-parsing"
 	HealthEndpoint        = "/health"
+	CapabilitiesEndpoint  = "/capabilities"
+
+	// TotalPagesHeader carries the document's page count, letting truncated
+	// responses be detected.
+	TotalPagesHeader = "X-Total-Pages"
 )
 
 // FileType represents the type of file being processed.
@@ -31,6 +63,8 @@ const (
 
 // OCRResult represents the OCR result for a single page.
 type OCRResult struct {
+	// PageIndex is the result's 0-based position in the response, or
+	// with OCROptions.PreserveServerIndices the page the server reported.
 	PageIndex int               `json:"page_index"`
 	Markdown  string            `json:"markdown"`
 	Images    map[string]string `json:"images"`
@@ -41,7 +75,28 @@ type DocumentOCRResult struct {
 	Success      bool        `json:"success"`
 	Pages        []OCRResult `json:"pages"`
 	ErrorMessage string      `json:"error_message,omitempty"`
-	LogID        string      `json:"log_id,omitempty"`
+	// ErrorID is the stable message ID of ErrorMessage, which is
+	// translated, so scripts can tell errors apart in any locale.
+	ErrorID   string `json:"error_id,omitempty"`
+	LogID     string `json:"log_id,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	// Err is a typed cause for the failure, when one is known.
+	Err error `json:"-"`
+	// ExpectedPages is the page count the server reported in the
+	// X-Total-Pages header, or 0 if it did not send one.
+	ExpectedPages int `json:"expected_pages,omitempty"`
+	// Partial is set when the server returned fewer pages than
+	// ExpectedPages.
+	Partial bool `json:"partial,omitempty"`
+	// Attempts is the number of HTTP requests sent, including retries and
+	// failover.
+	Attempts int `json:"-"`
+	// Warnings lists non-fatal issues noticed while processing the file,
+	// such as an unknown extension or an empty page.
+	Warnings []string `json:"warnings,omitempty"`
+	// Empty is set when the pages hold less text than
+	// OCROptions.MinChars, as for a blank or unreadable scan.
+	Empty bool `json:"empty,omitempty"`
 }
 
 // FullMarkdown returns combined markdown from all pages.
@@ -53,55 +108,333 @@ func (r *DocumentOCRResult) FullMarkdown() string {
 	return strings.Join(parts, "\n\n---\n\n")
 }
 
+// ClientOptions holds transport-level options for the client.
+type ClientOptions struct {
+	// ForceHTTP2 requires HTTP/2: negotiated via ALPN for https URLs and
+	// with prior knowledge (h2c) for http URLs.
+	ForceHTTP2 bool
+	// Insecure skips TLS certificate verification.
+	Insecure bool
+	// Logger receives diagnostic messages at debug level when non-nil.
+	Logger *log.Logger
+	// UserAgent overrides the User-Agent header. When empty, the config's
+	// user_agent or DefaultUserAgent is used.
+	UserAgent string
+	// ConnectTimeout limits how long establishing a connection may take,
+	// separately from the overall request timeout (default
+	// DefaultConnectTimeout).
+	ConnectTimeout time.Duration
+	// UnhealthyWindow is how long a server that failed to connect is
+	// skipped when several are configured (default lb.DefaultUnhealthyWindow).
+	UnhealthyWindow time.Duration
+}
+
 // Client is the PaddleOCR API client.
 type Client struct {
+	// APIVersion selects the wire format ("v1" or "v2"). When empty, the
+	// version is detected from the server's capabilities on first use,
+	// falling back to v1.
+	APIVersion string
+
 	config     *config.Config
 	httpClient *http.Client
+	transport  http.RoundTripper
+	log        *log.Logger
+	userAgent  string
+
+	tokenMu    sync.Mutex
+	tokens     []string
+	deadTokens map[int]bool
+	nextTok    int
+
+	servers  []string
+	basePath string
+	pool     *lb.ServerPool
+
+	capabilitiesOnce sync.Once
+	capabilities     *ServerCapabilities
+	capabilitiesErr  error
+	protocolOnce     sync.Once
+	codecOnce        sync.Once
+	apiCodec         apiCodec
 }
 
 // NewClient creates a new OCR client.
 func NewClient(cfg *config.Config) *Client {
+	return NewClientWithOptions(cfg, ClientOptions{})
+}
+
+// NewClientWithOptions creates a new OCR client with transport options.
+func NewClientWithOptions(cfg *config.Config, opts ClientOptions) *Client {
 	if cfg == nil {
 		cfg, _ = config.Load("")
+		if cfg == nil {
+			cfg = config.New()
+		}
+	}
+
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = cfg.PaddleOCR.UserAgent
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	connectTimeout := opts.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = DefaultConnectTimeout
+	}
+	dialer := &net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}
+
+	var tlsConfig *tls.Config
+	if opts.Insecure {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	base := newTransport(dialer)
+	base.TLSClientConfig = tlsConfig
+	var transport http.RoundTripper = base
+	if opts.ForceHTTP2 {
+		transport = newHTTP2Transport(dialer, tlsConfig)
+	}
+
+	var servers []string
+	for _, server := range cfg.PaddleOCR.Servers() {
+		servers = append(servers, strings.TrimRight(server, "/"))
 	}
+
 	return &Client{
-		config: cfg,
+		APIVersion: cfg.PaddleOCR.APIVersion,
+		config:     cfg,
 		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
+			Timeout:   120 * time.Second,
+			Transport: transport,
 		},
+		transport: transport,
+		log:       opts.Logger,
+		userAgent: userAgent,
+		tokens:    cfg.PaddleOCR.Tokens(),
+		servers:   servers,
+		basePath:  strings.TrimRight(cfg.PaddleOCR.BasePath, "/"),
+		pool:      lb.NewServerPool(servers, opts.UnhealthyWindow),
 	}
 }
 
+// newTransport returns a copy of the default transport that connects with
+// dialer.
+func newTransport(dialer *net.Dialer) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// newHTTPClient returns an HTTP client sharing the client's transport.
+func (c *Client) newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: c.transport}
+}
+
+// setHeaders sets the headers common to all requests, including the access
+// token and a new X-Request-ID, which it returns.
+func (c *Client) setHeaders(req *http.Request, token string) string {
+	requestID := uuid.NewString()
+	req.Header.Set("Authorization", c.authorization(token))
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("X-Request-ID", requestID)
+	c.debugf("%s %s (X-Request-ID: %s)", req.Method, req.URL, requestID)
+	return requestID
+}
+
+// debugf writes a diagnostic message to the logger, if any.
+func (c *Client) debugf(format string, args ...interface{}) {
+	c.log.Debugf(format, args...)
+}
+
+// logProtocol reports the negotiated protocol of the first successful response.
+func (c *Client) logProtocol(resp *http.Response) {
+	c.protocolOnce.Do(func() {
+		c.debugf("Negotiated protocol: %s", resp.Proto)
+	})
+}
+
 // IsConfigured checks if the client is properly configured.
 func (c *Client) IsConfigured() bool {
-	return c.config.IsConfigured()
+	return len(c.servers) > 0 && c.AccessToken() != ""
+}
+
+// AccessToken returns the token used for requests, or the first one when
+// several are configured.
+func (c *Client) AccessToken() string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if len(c.tokens) == 0 {
+		return ""
+	}
+	return c.tokens[0]
+}
+
+// SetAccessToken replaces the configured tokens with a single token for
+// subsequent requests.
+func (c *Client) SetAccessToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.tokens = []string{token}
+	c.deadTokens = nil
+	c.nextTok = 0
+}
+
+// nextToken picks the token for a request, round-robin over the tokens not
+// yet rejected, and returns its index, or -1 when all were rejected.
+func (c *Client) nextToken() (int, string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	for i := range c.tokens {
+		index := (c.nextTok + i) % len(c.tokens)
+		if !c.deadTokens[index] {
+			c.nextTok = index + 1
+			if len(c.tokens) > 1 {
+				c.debugf("Using access token #%d of %d", index+1, len(c.tokens))
+			}
+			return index, c.tokens[index]
+		}
+	}
+	return -1, ""
+}
+
+// rejectToken marks a token the server rejected as unusable for the rest
+// of the run when several are configured, and reports whether another
+// token remains to try. A single token is never marked, so auth failures
+// surface as before.
+func (c *Client) rejectToken(index int) bool {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if len(c.tokens) < 2 {
+		return false
+	}
+	if c.deadTokens == nil {
+		c.deadTokens = make(map[int]bool)
+	}
+	c.deadTokens[index] = true
+	return len(c.deadTokens) < len(c.tokens)
 }
 
-// ServerURL returns the configured server URL.
+// ServerURL returns the configured server URL, or the first one when
+// several are configured.
 func (c *Client) ServerURL() string {
-	return strings.TrimRight(c.config.PaddleOCR.ServerURL, "/")
+	if len(c.servers) == 0 {
+		return ""
+	}
+	return c.servers[0]
+}
+
+// GetEndpoint returns the full URL of an endpoint, such as HealthEndpoint,
+// on the first configured server, including any base_path.
+func (c *Client) GetEndpoint(endpoint string) string {
+	return c.endpointURL(c.ServerURL(), endpoint)
+}
+
+// endpointURL joins a server URL, the configured base path and an endpoint.
+func (c *Client) endpointURL(server, endpoint string) string {
+	return server + c.basePath + endpoint
+}
+
+// ServerURLs returns all configured server URLs.
+func (c *Client) ServerURLs() []string {
+	return c.servers
 }
 
 // getFileType determines file type from extension.
 func getFileType(filePath string) FileType {
+	fileType, _ := detectFileType(filePath)
+	return fileType
+}
+
+// detectFileType determines file type from extension, reporting whether the
+// extension was recognized. Unknown extensions are treated as images.
+func detectFileType(filePath string) (FileType, bool) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	switch ext {
 	case ".pdf":
-		return FileTypePDF
+		return FileTypePDF, true
 	case ".png", ".jpg", ".jpeg", ".bmp", ".tiff", ".tif", ".webp":
-		return FileTypeImage
+		return FileTypeImage, true
 	default:
-		return FileTypeImage
+		return FileTypeImage, false
+	}
+}
+
+// sniffWarning returns a warning when the file's content contradicts the
+// type it is being sent as, or "" when it does not.
+func sniffWarning(fileData []byte, fileType FileType) string {
+	isPDF := bytes.HasPrefix(fileData, []byte("%PDF-"))
+	switch {
+	case fileType == FileTypePDF && !isPDF:
+		return "content does not look like a PDF"
+	case fileType == FileTypeImage && isPDF:
+		return "content looks like a PDF but is being sent as an image"
+	}
+	return ""
+}
+
+// CompressPayload gzips a request body.
+func CompressPayload(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
 }
 
-// encodeFile encodes a file to base64.
-func encodeFile(filePath string) (string, error) {
-	data, err := os.ReadFile(filePath)
+// maxResponseSize bounds the size of a response body, after
+// decompression, as a sanity limit against malformed or hostile servers.
+var maxResponseSize int64 = 1 << 30
+
+// readBody reads a response body, decompressing it when the server sent
+// Content-Encoding: gzip. Go's transport only decompresses transparently
+// when it requested gzip itself, so servers and proxies that compress
+// unconditionally are handled here. Bodies larger than maxResponseSize are
+// rejected.
+func readBody(resp *http.Response) ([]byte, error) {
+	var reader io.Reader = resp.Body
+	if strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip") {
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip response: %w", err)
+		}
+		defer zr.Close()
+		reader = zr
+	}
+
+	body, err := io.ReadAll(io.LimitReader(reader, maxResponseSize+1))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return base64.StdEncoding.EncodeToString(data), nil
+	if int64(len(body)) > maxResponseSize {
+		return nil, fmt.Errorf("response larger than %d MB", maxResponseSize>>20)
+	}
+	return body, nil
+}
+
+// errorLogID returns the log id in the body of an error response, or "" if
+// the body is not an API response.
+func errorLogID(codec api.Decoder, body []byte) string {
+	response, err := codec.Decode(body)
+	if err != nil {
+		return ""
+	}
+	return response.LogID
+}
+
+// looksLikeHTML reports whether a response is an HTML page rather than JSON.
+func looksLikeHTML(contentType string, body []byte) bool {
+	if strings.Contains(strings.ToLower(contentType), "text/html") {
+		return true
+	}
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '<'
 }
 
 // OCROptions holds options for OCR processing.
@@ -110,6 +443,48 @@ type OCROptions struct {
 	UseDocUnwarping           bool
 	UseChartRecognition       bool
 	Timeout                   time.Duration
+	PDFPassword               string
+	// Retries is the number of times a request is retried after a
+	// connection error, HTTP 429 or HTTP 5xx response.
+	Retries int
+	// RetryBudget caps the total time spent on a request including retries.
+	// When set, transient failures are retried until it is spent, limited
+	// by Retries only if that is positive.
+	RetryBudget time.Duration
+	// Compress gzips the JSON request body. Callers should check
+	// SupportsFeature(FeatureGzip) first.
+	Compress bool
+	// Multipart uploads the raw file as multipart/form-data when the server
+	// supports it, falling back to base64 JSON otherwise.
+	Multipart bool
+	// OnRateLimit, when set, makes retries of HTTP 429 responses wait for
+	// the delay given by Retry-After or X-RateLimit-Reset instead of the
+	// exponential backoff. It is called with the delay before waiting.
+	OnRateLimit func(wait time.Duration)
+	// MaxDimension, when positive, downscales images whose width or height
+	// exceeds it and re-encodes them as JPEG at JPEGQuality before upload.
+	MaxDimension int
+	// JPEGQuality is the quality (1-100) for downscaled images; 0 uses
+	// imageutil.DefaultJPEGQuality.
+	JPEGQuality int
+	// Crop, when set, sends only this rectangle of an image, re-encoded as
+	// PNG, before any downscaling. Other file types are sent whole.
+	Crop *imageutil.Rect
+	// OnPhase, when set, is called as the request moves through its
+	// phases, for progress display. size is the upload size in bytes for
+	// PhaseUploading and 0 otherwise. It may be called from the transport's
+	// goroutines.
+	OnPhase func(phase Phase, size int64)
+	// PreserveServerIndices sets each page's PageIndex to the page number
+	// the server reported for it, when it reported one, instead of its
+	// position in the response.
+	PreserveServerIndices bool
+	// MinChars is the text length, as counted by format.TextLength, below
+	// which a successful result is marked Empty. Values below 1 mean 1.
+	MinChars int
+	// MaxPages rejects a response listing more pages than this, as a
+	// sanity limit against malformed or hostile servers (0 = no limit).
+	MaxPages int
 }
 
 // DefaultOCROptions returns default OCR options.
@@ -119,91 +494,422 @@ func DefaultOCROptions() OCROptions {
 	}
 }
 
+// doWithRetry sends a request, retrying transient failures with exponential
+// backoff, or after the server's requested delay for rate-limited requests
+// when opts.OnRateLimit is set. With a budget, retries stop once it is
+// spent. Requests without GetBody are sent only once. The number of
+// attempts made is returned with the outcome.
+func (c *Client) doWithRetry(client *http.Client, req *http.Request, opts OCROptions) (*http.Response, int, error) {
+	if opts.RetryBudget > 0 {
+		return c.doWithBudget(client, req, opts)
+	}
+
+	backoff := retry.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= opts.Retries || req.GetBody == nil || !isRetryable(resp, err) {
+			return resp, attempt + 1, err
+		}
+
+		wait := backoff
+		if delay := rateLimitDelay(resp, opts); delay > 0 {
+			opts.OnRateLimit(delay)
+			wait = delay
+		}
+		c.debugf("Attempt %d failed (%s); retrying in %s", attempt+1, retryCause(resp, err), wait)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, attempt + 1, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, attempt + 1, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+}
+
+// doWithBudget retries transient failures until opts.RetryBudget is spent,
+// and also after opts.Retries attempts when that is positive.
+func (c *Client) doWithBudget(client *http.Client, req *http.Request, opts OCROptions) (*http.Response, int, error) {
+	var resp *http.Response
+	var lastErr error
+	attempt := 0
+	retries := opts.Retries
+	deadline := time.Now().Add(opts.RetryBudget)
+	err := retry.RetryWithBudget(req.Context(), opts.RetryBudget, func() error {
+		if attempt > 0 {
+			if resp != nil {
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				resp = nil
+			}
+
+			body, err := req.GetBody()
+			if err != nil {
+				return retry.Permanent(err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		attempt++
+
+		resp, lastErr = client.Do(req)
+		if !isRetryable(resp, lastErr) || req.GetBody == nil || (retries > 0 && attempt > retries) {
+			return nil
+		}
+		if lastErr != nil {
+			c.debugf("Attempt %d failed (%s); retrying", attempt, retryCause(nil, lastErr))
+			return lastErr
+		}
+		c.debugf("Attempt %d failed (%s); retrying", attempt, retryCause(resp, nil))
+		err := fmt.Errorf("HTTP %d", resp.StatusCode)
+		if delay := rateLimitDelay(resp, opts); delay > 0 {
+			if time.Until(deadline) >= delay {
+				opts.OnRateLimit(delay)
+			}
+			return retry.After(err, delay)
+		}
+		return err
+	})
+	if resp == nil && lastErr == nil {
+		return nil, attempt, err
+	}
+	return resp, attempt, lastErr
+}
+
+// retryCause describes why an attempt is retried, for debug output.
+func retryCause(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "HTTP " + resp.Status
+}
+
+// rateLimitDelay returns the delay a rate-limited response asks for, or 0
+// if it is not rate limited or opts.OnRateLimit is unset.
+func rateLimitDelay(resp *http.Response, opts OCROptions) time.Duration {
+	if opts.OnRateLimit == nil || resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return 0
+	}
+	return ratelimit.ParseRetryAfter(resp.Header)
+}
+
+// isRetryable reports whether a request outcome is worth retrying.
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
 // OCRFile performs OCR on a file.
 func (c *Client) OCRFile(filePath string, opts OCROptions) *DocumentOCRResult {
-	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	return c.OCRFileCtx(context.Background(), filePath, opts)
+}
+
+// OCRFileCtx performs OCR on a file, abandoning the request when ctx is
+// cancelled.
+func (c *Client) OCRFileCtx(ctx context.Context, filePath string, opts OCROptions) *DocumentOCRResult {
+	if err := CheckFile(filePath); err != nil {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("File not found: %s", filePath),
+			ErrorID:      i18n.ErrorID(err),
+			ErrorMessage: err.Error(),
 		}
 	}
 
-	// Check if configured
-	if !c.IsConfigured() {
+	// Read file
+	fileData, err := os.ReadFile(filePath)
+	if os.IsPermission(err) {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: "PaddleOCR is not configured. Run 'paddleocr-cli configure' first.",
+			ErrorID:      "ocr.permission_denied",
+			ErrorMessage: i18n.T("ocr.permission_denied", "Permission denied: %s", filePath),
 		}
 	}
-
-	// Encode file
-	fileData, err := encodeFile(filePath)
 	if err != nil {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("Failed to read file: %v", err),
+			ErrorID:      "ocr.read_failed",
+			ErrorMessage: i18n.T("ocr.read_failed", "Failed to read file: %v", err),
 		}
 	}
 
-	// Prepare request payload
-	payload := map[string]interface{}{
-		"file":                     fileData,
-		"fileType":                 int(getFileType(filePath)),
-		"useDocOrientationClassify": opts.UseDocOrientationClassify,
-		"useDocUnwarping":          opts.UseDocUnwarping,
-		"useChartRecognition":      opts.UseChartRecognition,
+	fileType, known := detectFileType(filePath)
+	result := c.OCRBytesCtx(ctx, fileData, fileType, filepath.Base(filePath), opts)
+	if !known {
+		result.Warnings = append([]string{unknownExtensionWarning(filePath)}, result.Warnings...)
 	}
+	return result
+}
 
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
+// OCRBytes performs OCR on in-memory file data of the given type. name is
+// used as the upload's file name in multipart requests.
+func (c *Client) OCRBytes(fileData []byte, fileType FileType, name string, opts OCROptions) *DocumentOCRResult {
+	return c.OCRBytesCtx(context.Background(), fileData, fileType, name, opts)
+}
+
+// OCRBytesCtx is OCRBytes, abandoning the request when ctx is cancelled.
+func (c *Client) OCRBytesCtx(ctx context.Context, fileData []byte, fileType FileType, name string, opts OCROptions) (result *DocumentOCRResult) {
+	var requestID string
+	var attempts int
+	var warnings []string
+	defer func() {
+		result.RequestID = requestID
+		result.Attempts = attempts
+		result.Warnings = append(warnings, result.Warnings...)
+	}()
+
+	if warning := sniffWarning(fileData, fileType); warning != "" {
+		warnings = append(warnings, warning)
+	}
+
+	// Check if configured
+	if !c.IsConfigured() {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("Failed to marshal payload: %v", err),
+			ErrorID:      "ocr.not_configured",
+			ErrorMessage: i18n.T("ocr.not_configured", "PaddleOCR is not configured. Run 'paddleocr-cli configure' first."),
 		}
 	}
+	opts.reportPhase(PhaseEncoding, 0)
 
-	// Create request
-	url := c.ServerURL() + LayoutParsingEndpoint
-	req, err := http.NewRequest("POST", url, bytes.NewReader(payloadBytes))
-	if err != nil {
-		return &DocumentOCRResult{
-			Success:      false,
-			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("Failed to create request: %v", err),
+	// Decrypt encrypted PDFs in memory
+	if fileType == FileTypePDF && pdfutil.IsEncrypted(fileData) {
+		decrypted, err := pdfutil.Decrypt(fileData, opts.PDFPassword)
+		if err != nil {
+			return &DocumentOCRResult{
+				Success:      false,
+				Pages:        []OCRResult{},
+				ErrorID:      "ocr.decrypt_failed",
+				ErrorMessage: err.Error(),
+				Err:          err,
+			}
+		}
+		fileData = decrypted
+	}
+
+	// Crop images to the requested region
+	if opts.Crop != nil {
+		if fileType != FileTypeImage {
+			warnings = append(warnings, "not cropped: only images can be cropped")
+		} else {
+			cropped, err := imageutil.Crop(fileData, *opts.Crop)
+			if err != nil {
+				return &DocumentOCRResult{
+					Success:      false,
+					Pages:        []OCRResult{},
+					ErrorID:      "ocr.crop_failed",
+					ErrorMessage: i18n.T("ocr.crop_failed", "Cannot crop %s: %v", name, err),
+				}
+			}
+			c.debugf("Cropped %s to %s (%d -> %d bytes)", name, opts.Crop, len(fileData), len(cropped))
+			fileData = cropped
+			name = strings.TrimSuffix(name, filepath.Ext(name)) + ".png"
+		}
+	}
+
+	// Downscale large images
+	if fileType == FileTypeImage && opts.MaxDimension > 0 {
+		quality := opts.JPEGQuality
+		if quality <= 0 {
+			quality = imageutil.DefaultJPEGQuality
+		}
+		scaled, err := imageutil.Downscale(fileData, opts.MaxDimension, quality)
+		switch {
+		case err != nil:
+			warnings = append(warnings, fmt.Sprintf("not downscaled: %v", err))
+		case scaled != nil && len(scaled.Data) >= len(fileData):
+			c.debugf("Not downscaling %s: the JPEG would be larger (%d -> %d bytes)", name, len(fileData), len(scaled.Data))
+		case scaled != nil:
+			c.debugf("Downscaled %s from %dx%d to %dx%d (%d -> %d bytes)", name,
+				scaled.OrigWidth, scaled.OrigHeight, scaled.Width, scaled.Height, len(fileData), len(scaled.Data))
+			fileData = scaled.Data
+			name = strings.TrimSuffix(name, filepath.Ext(name)) + ".jpg"
+		}
+	}
+
+	// Prepare request payload
+	codec := c.codec(ctx)
+	request := &api.Request{
+		FileType: int(fileType),
+		Features: map[string]bool{
+			api.FeatureDocOrientationClassify: opts.UseDocOrientationClassify,
+			api.FeatureDocUnwarping:           opts.UseDocUnwarping,
+			api.FeatureChartRecognition:       opts.UseChartRecognition,
+		},
+	}
+	c.filterFeatures(ctx, request)
+	payload := codec.Encode(request)
+
+	var payloadBytes []byte
+	var newBody func() io.ReadCloser
+	contentType := "application/json"
+	contentEncoding := ""
+	if opts.Multipart && c.supportsMultipart(ctx) {
+		newBody, contentType = multipartBody(codec.FileField(), name, fileData, payload)
+	} else {
+		payload[codec.FileField()] = base64.StdEncoding.EncodeToString(fileData)
+		var err error
+		payloadBytes, err = json.Marshal(payload)
+		if err != nil {
+			return &DocumentOCRResult{
+				Success:      false,
+				Pages:        []OCRResult{},
+				ErrorID:      "ocr.marshal_failed",
+				ErrorMessage: i18n.T("ocr.marshal_failed", "Failed to marshal payload: %v", err),
+			}
+		}
+		if opts.Compress {
+			compressed, err := CompressPayload(payloadBytes)
+			if err != nil {
+				return &DocumentOCRResult{
+					Success:      false,
+					Pages:        []OCRResult{},
+					ErrorID:      "ocr.compress_failed",
+					ErrorMessage: i18n.T("ocr.compress_failed", "Failed to compress payload: %v", err),
+				}
+			}
+			c.debugf("Compressed payload: %d -> %d bytes", len(payloadBytes), len(compressed))
+			payloadBytes = compressed
+			contentEncoding = "gzip"
 		}
 	}
 
-	req.Header.Set("Authorization", "token "+c.config.PaddleOCR.AccessToken)
-	req.Header.Set("Content-Type", "application/json")
+	uploadSize := int64(len(payloadBytes))
+	if newBody != nil {
+		uploadSize = int64(len(fileData))
+	}
+	ctx = withPhaseTrace(ctx, opts, uploadSize)
 
 	// Set timeout
 	client := c.httpClient
 	if opts.Timeout > 0 {
-		client = &http.Client{Timeout: opts.Timeout}
+		client = c.newHTTPClient(opts.Timeout)
 	}
 
-	// Send request
-	resp, err := client.Do(req)
+	// Send request, failing over to the next server on connection errors
+	// and to the next access token when one is rejected
+	var resp *http.Response
+	for {
+		tokenIndex, token := c.nextToken()
+		if tokenIndex < 0 {
+			return &DocumentOCRResult{
+				Success:      false,
+				Pages:        []OCRResult{},
+				ErrorID:      "ocr.tokens_rejected",
+				ErrorMessage: i18n.T("ocr.tokens_rejected", "All access tokens were rejected"),
+				Err:          ErrUnauthorized,
+			}
+		}
+
+		servers := c.pool.Rotation()
+		for i, server := range servers {
+			if i == 0 {
+				c.debugf("Using server %s", server)
+			} else {
+				c.debugf("Trying next server %s", server)
+			}
+
+			var reqBody io.Reader = bytes.NewReader(payloadBytes)
+			if newBody != nil {
+				reqBody = newBody()
+			}
+
+			req, err := http.NewRequestWithContext(ctx, "POST", c.endpointURL(server, LayoutParsingEndpoint), reqBody)
+			if err != nil {
+				return &DocumentOCRResult{
+					Success:      false,
+					Pages:        []OCRResult{},
+					ErrorID:      "ocr.create_request_failed",
+					ErrorMessage: i18n.T("ocr.create_request_failed", "Failed to create request: %v", err),
+				}
+			}
+
+			if newBody != nil {
+				req.GetBody = func() (io.ReadCloser, error) { return newBody(), nil }
+			}
+
+			requestID = c.setHeaders(req, token)
+			req.Header.Set("Content-Type", contentType)
+			if contentEncoding != "" {
+				req.Header.Set("Content-Encoding", contentEncoding)
+			}
+
+			var n int
+			resp, n, err = c.doWithRetry(client, req, opts)
+			attempts += n
+			if err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return &DocumentOCRResult{
+					Success:      false,
+					Pages:        []OCRResult{},
+					ErrorID:      "ocr.request_cancelled",
+					ErrorMessage: i18n.T("ocr.request_cancelled", "Request cancelled"),
+					Err:          ctx.Err(),
+				}
+			}
+			if len(servers) > 1 {
+				c.debugf("Request to %s failed, marking it unhealthy: %v", server, err)
+				c.pool.MarkUnhealthy(server)
+			}
+			if i == len(servers)-1 {
+				return &DocumentOCRResult{
+					Success:      false,
+					Pages:        []OCRResult{},
+					ErrorID:      "ocr.request_failed",
+					ErrorMessage: i18n.T("ocr.request_failed", "Request failed: %v", err),
+				}
+			}
+		}
+
+		if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && c.rejectToken(tokenIndex) {
+			c.debugf("Access token #%d rejected (HTTP %d); trying the next one", tokenIndex+1, resp.StatusCode)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+		break
+	}
+	defer resp.Body.Close()
+
+	opts.reportPhase(PhaseParsing, 0)
+	body, err := readBody(resp)
 	if err != nil {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("Request failed: %v", err),
+			ErrorID:      "ocr.read_response_failed",
+			ErrorMessage: i18n.T("ocr.read_response_failed", "Failed to read response: %v", err),
 		}
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	if resp.StatusCode == http.StatusUnauthorized {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("Failed to read response: %v", err),
+			ErrorID:      "ocr.http_status",
+			ErrorMessage: i18n.T("ocr.http_status", "HTTP %d: %s", resp.StatusCode, resp.Status) + "\n" + string(body),
+			Err:          ErrUnauthorized,
+			LogID:        errorLogID(codec, body),
 		}
 	}
 
@@ -211,30 +917,45 @@ func (c *Client) OCRFile(filePath string, opts OCROptions) *DocumentOCRResult {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("HTTP %d: %s\n%s", resp.StatusCode, resp.Status, string(body)),
+			ErrorID:      "ocr.http_status",
+			ErrorMessage: i18n.T("ocr.http_status", "HTTP %d: %s", resp.StatusCode, resp.Status) + "\n" + string(body),
+			LogID:        errorLogID(codec, body),
 		}
 	}
 
-	// Parse response
-	var response struct {
-		LogID     string `json:"logId"`
-		ErrorCode int    `json:"errorCode"`
-		ErrorMsg  string `json:"errorMsg"`
-		Result    struct {
-			LayoutParsingResults []struct {
-				Markdown struct {
-					Text   string            `json:"text"`
-					Images map[string]string `json:"images"`
-				} `json:"markdown"`
-			} `json:"layoutParsingResults"`
-		} `json:"result"`
+	c.logProtocol(resp)
+
+	if looksLikeHTML(resp.Header.Get("Content-Type"), body) {
+		return &DocumentOCRResult{
+			Success:      false,
+			Pages:        []OCRResult{},
+			ErrorID:      "ocr.html_response",
+			ErrorMessage: i18n.T("ocr.html_response", "Server returned HTML (likely an auth/proxy error page), not JSON (HTTP %s)", resp.Status),
+		}
 	}
 
-	if err := json.Unmarshal(body, &response); err != nil {
+	// The page count is checked before the pages are decoded, so a
+	// hostile response cannot make us allocate them.
+	if opts.MaxPages > 0 {
+		if header, pages, err := codec.DecodeHeader(body); err == nil && header.ErrorCode == 0 && pages > opts.MaxPages {
+			return &DocumentOCRResult{
+				Success:      false,
+				Pages:        []OCRResult{},
+				ErrorID:      "ocr.too_many_pages",
+				ErrorMessage: i18n.T("ocr.too_many_pages", "Invalid response: %d pages, more than the limit of %d", pages, opts.MaxPages),
+				LogID:        header.LogID,
+			}
+		}
+	}
+
+	// Parse response
+	response, err := codec.Decode(body)
+	if err != nil {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("Invalid JSON response: %v", err),
+			ErrorID:      "ocr.invalid_json",
+			ErrorMessage: i18n.T("ocr.invalid_json", "Invalid JSON response: %v", err),
 		}
 	}
 
@@ -242,60 +963,107 @@ func (c *Client) OCRFile(filePath string, opts OCROptions) *DocumentOCRResult {
 		return &DocumentOCRResult{
 			Success:      false,
 			Pages:        []OCRResult{},
-			ErrorMessage: fmt.Sprintf("API error (%d): %s", response.ErrorCode, response.ErrorMsg),
+			ErrorID:      "ocr.api_error",
+			ErrorMessage: i18n.T("ocr.api_error", "API error (%d): %s", response.ErrorCode, response.ErrorMsg),
 			LogID:        response.LogID,
 		}
 	}
 
 	// Build result
 	var pages []OCRResult
-	for i, layoutResult := range response.Result.LayoutParsingResults {
-		images := layoutResult.Markdown.Images
+	for i, page := range response.Pages {
+		images := page.Images
 		if images == nil {
 			images = make(map[string]string)
 		}
+		index := i
+		if opts.PreserveServerIndices && page.Index != nil {
+			index = *page.Index
+		}
 		pages = append(pages, OCRResult{
-			PageIndex: i,
-			Markdown:  layoutResult.Markdown.Text,
+			PageIndex: index,
+			Markdown:  page.Markdown,
 			Images:    images,
 		})
+		if strings.TrimSpace(page.Markdown) == "" {
+			warnings = append(warnings, fmt.Sprintf("page %d is empty", index+1))
+		}
 	}
 
-	return &DocumentOCRResult{
+	result = &DocumentOCRResult{
 		Success: true,
 		Pages:   pages,
 		LogID:   response.LogID,
 	}
+	chars := 0
+	for _, page := range pages {
+		chars += format.TextLength(page.Markdown)
+	}
+	result.Empty = chars < max(opts.MinChars, 1)
+	if expected, err := strconv.Atoi(resp.Header.Get(TotalPagesHeader)); err == nil && expected > 0 {
+		result.ExpectedPages = expected
+		result.Partial = len(pages) < expected
+	}
+	if result.Partial {
+		warnings = append(warnings, fmt.Sprintf("server returned %d of %d pages; the result is incomplete", len(pages), result.ExpectedPages))
+	}
+	return result
 }
 
-// TestConnection tests the connection to the OCR server.
+// TestConnection tests the connection to the OCR server. With several
+// servers configured, each is checked and the result is successful only if
+// all of them are reachable.
 func (c *Client) TestConnection() (bool, string) {
-	if c.config.PaddleOCR.AccessToken == "" {
-		return false, "Access token not configured"
+	if c.AccessToken() == "" {
+		return false, i18n.T("ocr.no_token", "Access token not configured")
+	}
+	if len(c.servers) == 0 {
+		return false, i18n.T("ocr.no_server", "No server configured")
+	}
+	if len(c.servers) == 1 {
+		return c.testServer(c.servers[0])
 	}
 
-	url := c.ServerURL() + HealthEndpoint
+	allOK := true
+	var lines []string
+	for _, server := range c.servers {
+		ok, message := c.testServer(server)
+		allOK = allOK && ok
+		lines = append(lines, fmt.Sprintf("%s: %s", server, message))
+	}
+	return allOK, strings.Join(lines, "\n")
+}
+
+// testServer checks the health endpoint of a single server.
+func (c *Client) testServer(server string) (bool, string) {
+	url := c.endpointURL(server, HealthEndpoint)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return false, fmt.Sprintf("Failed to create request: %v", err)
+		return false, i18n.T("ocr.create_request_failed", "Failed to create request: %v", err)
 	}
 
-	req.Header.Set("Authorization", "token "+c.config.PaddleOCR.AccessToken)
+	c.setHeaders(req, c.AccessToken())
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := c.newHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
-		return false, fmt.Sprintf("Connection failed: %v", err)
+		return false, i18n.T("ocr.connection_failed", "Connection failed: %v", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readBody(resp)
 	if err != nil {
-		return false, fmt.Sprintf("Failed to read response: %v", err)
+		return false, i18n.T("ocr.read_response_failed", "Failed to read response: %v", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return false, fmt.Sprintf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		return false, i18n.T("ocr.http_status", "HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	c.logProtocol(resp)
+
+	if looksLikeHTML(resp.Header.Get("Content-Type"), body) {
+		return false, i18n.T("ocr.html_response", "Server returned HTML (likely an auth/proxy error page), not JSON (HTTP %s)", resp.Status)
 	}
 
 	var response struct {
@@ -304,12 +1072,12 @@ func (c *Client) TestConnection() (bool, string) {
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return false, fmt.Sprintf("Invalid JSON response: %v", err)
+		return false, i18n.T("ocr.invalid_json", "Invalid JSON response: %v", err)
 	}
 
 	if response.ErrorCode == 0 {
-		return true, "Connection successful"
+		return true, i18n.T("ocr.connection_ok", "Connection successful")
 	}
 
-	return false, fmt.Sprintf("Server error: %s", response.ErrorMsg)
+	return false, i18n.T("ocr.server_error", "Server error: %s", response.ErrorMsg)
 }
@@ -0,0 +1,229 @@
+package ocr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/config"
+)
+
+func TestTestConnectionWithoutServer(t *testing.T) {
+	cfg := config.New()
+	cfg.PaddleOCR.AccessToken = "token"
+
+	ok, message := NewClient(cfg).TestConnection()
+	if ok {
+		t.Fatalf("TestConnection() succeeded with no server configured: %q", message)
+	}
+	if message != "No server configured" {
+		t.Errorf("message = %q, want %q", message, "No server configured")
+	}
+}
+
+// gzipped returns data compressed with gzip.
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadBodyGzip(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "layout_parsing_v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{"identity", "", fixture},
+		{"gzip", "gzip", gzipped(t, fixture)},
+		{"gzip mixed case", " GZip ", gzipped(t, fixture)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header: http.Header{},
+				Body:   io.NopCloser(bytes.NewReader(tt.body)),
+			}
+			if tt.encoding != "" {
+				resp.Header.Set("Content-Encoding", tt.encoding)
+			}
+			got, err := readBody(resp)
+			if err != nil {
+				t.Fatalf("readBody: %v", err)
+			}
+			if !bytes.Equal(got, fixture) {
+				t.Errorf("readBody() = %q, want the fixture", got)
+			}
+		})
+	}
+
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": {"gzip"}},
+		Body:   io.NopCloser(bytes.NewReader(fixture)),
+	}
+	if _, err := readBody(resp); err == nil {
+		t.Error("readBody accepted an uncompressed body labelled gzip")
+	}
+}
+
+func TestOCRBytesGzipRequestAndResponse(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "layout_parsing_v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileData := []byte("fake image bytes")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != LayoutParsingEndpoint {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("request Content-Encoding = %q, want gzip", r.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("request body is not gzip: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var payload map[string]interface{}
+		if err := json.NewDecoder(zr).Decode(&payload); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		if payload["file"] != base64.StdEncoding.EncodeToString(fileData) {
+			t.Errorf("request file = %v, want the base64 input", payload["file"])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipped(t, fixture))
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	cfg.PaddleOCR.ServerURL = srv.URL
+	cfg.PaddleOCR.AccessToken = "token"
+	client := NewClient(cfg)
+	client.APIVersion = "v1"
+
+	opts := DefaultOCROptions()
+	opts.Compress = true
+	result := client.OCRBytes(fileData, FileTypeImage, "scan.png", opts)
+	if !result.Success {
+		t.Fatalf("OCRBytes failed: %s", result.ErrorMessage)
+	}
+	if len(result.Pages) != 2 || result.Pages[0].Markdown != "# Invoice\n\nTotal: 42" {
+		t.Errorf("pages = %+v, want the two fixture pages", result.Pages)
+	}
+	if result.LogID != "log-123" {
+		t.Errorf("LogID = %q, want log-123", result.LogID)
+	}
+}
+
+func TestReadBodyLimit(t *testing.T) {
+	defer func(limit int64) { maxResponseSize = limit }(maxResponseSize)
+	maxResponseSize = 16
+
+	// The limit applies after decompression, so a small gzip body that
+	// expands beyond it is rejected too.
+	large := bytes.Repeat([]byte("a"), 17)
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		wantErr  bool
+	}{
+		{"at the limit", "", large[:16], false},
+		{"over the limit", "", large, true},
+		{"gzip over the limit", "gzip", gzipped(t, large), true},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(tt.body))}
+		if tt.encoding != "" {
+			resp.Header.Set("Content-Encoding", tt.encoding)
+		}
+		if _, err := readBody(resp); (err != nil) != tt.wantErr {
+			t.Errorf("%s: readBody() error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOCRBytesMaxPages(t *testing.T) {
+	fixture, err := os.ReadFile(filepath.Join("testdata", "layout_parsing_v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+	defer srv.Close()
+
+	cfg := config.New()
+	cfg.PaddleOCR.ServerURL = srv.URL
+	cfg.PaddleOCR.AccessToken = "token"
+	client := NewClient(cfg)
+	client.APIVersion = "v1"
+
+	// The fixture lists two pages.
+	for _, tt := range []struct {
+		maxPages int
+		success  bool
+	}{{0, true}, {2, true}, {1, false}} {
+		opts := DefaultOCROptions()
+		opts.MaxPages = tt.maxPages
+		result := client.OCRBytes([]byte("fake image bytes"), FileTypeImage, "scan.png", opts)
+		if result.Success != tt.success {
+			t.Errorf("MaxPages %d: success = %v (%s), want %v", tt.maxPages, result.Success, result.ErrorMessage, tt.success)
+		}
+		if !tt.success && result.LogID != "log-123" {
+			t.Errorf("MaxPages %d: LogID = %q, want log-123", tt.maxPages, result.LogID)
+		}
+	}
+}
+
+func TestFetchCapabilitiesHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := config.New()
+	cfg.PaddleOCR.ServerURL = srv.URL
+	cfg.PaddleOCR.AccessToken = "token"
+	client := NewClient(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if _, err := client.FetchCapabilities(ctx); err == nil {
+		t.Fatal("FetchCapabilities succeeded with a cancelled context")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("FetchCapabilities took %s after cancellation", elapsed)
+	}
+}
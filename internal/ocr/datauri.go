@@ -0,0 +1,84 @@
+package ocr
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"sort"
+	"strings"
+)
+
+// dataURIExtensions maps the media types accepted in data URIs to the file
+// extension used for naming and type detection.
+var dataURIExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/jpg":       ".jpg",
+	"image/bmp":       ".bmp",
+	"image/tiff":      ".tiff",
+	"image/webp":      ".webp",
+}
+
+// DataURI is a decoded base64 data URI input.
+type DataURI struct {
+	MediaType string
+	// Ext is the file extension matching MediaType, such as ".png".
+	Ext  string
+	Data []byte
+}
+
+// FileType returns the OCR file type for the data's media type.
+func (d *DataURI) FileType() FileType {
+	return getFileType(d.Ext)
+}
+
+// IsDataURI reports whether s looks like a data URI rather than a path.
+func IsDataURI(s string) bool {
+	return strings.HasPrefix(s, "data:")
+}
+
+// ParseDataURI decodes a base64 data URI such as
+// "data:image/png;base64,iVBOR...". Only the image and PDF media types the
+// server accepts are allowed.
+func ParseDataURI(s string) (*DataURI, error) {
+	if !IsDataURI(s) {
+		return nil, fmt.Errorf("not a data URI")
+	}
+	header, payload, ok := strings.Cut(strings.TrimPrefix(s, "data:"), ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid data URI: missing ','")
+	}
+
+	params := strings.Split(header, ";")
+	if params[len(params)-1] != "base64" {
+		return nil, fmt.Errorf("invalid data URI: only base64 encoding is supported")
+	}
+	mediaType, _, err := mime.ParseMediaType(params[0])
+	if params[0] == "" || err != nil {
+		return nil, fmt.Errorf("invalid data URI: missing or malformed media type")
+	}
+	ext, ok := dataURIExtensions[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported data URI media type %q (supported: %s)", mediaType, strings.Join(dataURIMediaTypes(), ", "))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+	if err != nil {
+		return nil, fmt.Errorf("invalid data URI: %v", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("data URI is empty")
+	}
+	return &DataURI{MediaType: mediaType, Ext: ext, Data: data}, nil
+}
+
+// dataURIMediaTypes returns the accepted media types, sorted.
+func dataURIMediaTypes() []string {
+	var types []string
+	for mediaType := range dataURIExtensions {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,35 @@
+package ocr
+
+import (
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr/api"
+)
+
+// renamingEncoder renames the top-level request fields of an API version's
+// encoder, for servers that expect different names (paddleocr.request_fields
+// in the config). Fields without a mapping keep their standard names.
+type renamingEncoder struct {
+	api.Encoder
+	names map[string]string
+}
+
+// Encode implements api.Encoder.
+func (e renamingEncoder) Encode(req *api.Request) map[string]interface{} {
+	payload := e.Encoder.Encode(req)
+	renamed := make(map[string]interface{}, len(payload))
+	for name, value := range payload {
+		renamed[e.rename(name)] = value
+	}
+	return renamed
+}
+
+// FileField implements api.Encoder.
+func (e renamingEncoder) FileField() string {
+	return e.rename(e.Encoder.FileField())
+}
+
+func (e renamingEncoder) rename(name string) string {
+	if mapped := e.names[name]; mapped != "" {
+		return mapped
+	}
+	return name
+}
@@ -0,0 +1,70 @@
+package ocr
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// http2OnlyTransport routes https requests through an HTTP/2 transport that
+// requires h2 to be negotiated via ALPN, and http requests through an h2c
+// transport.
+type http2OnlyTransport struct {
+	tls *http2.Transport
+	h2c *http2.Transport
+}
+
+// newHTTP2Transport returns a round tripper that forces HTTP/2, connecting
+// with dialer and tlsConfig (nil for the defaults). A server that only
+// offers HTTP/1.1 fails the request rather than being used over HTTP/1.1.
+func newHTTP2Transport(dialer *net.Dialer, tlsConfig *tls.Config) http.RoundTripper {
+	return &http2OnlyTransport{
+		tls: &http2.Transport{
+			TLSClientConfig: tlsConfig,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return dialHTTP2TLS(ctx, dialer, network, addr, cfg)
+			},
+		},
+		h2c: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+}
+
+// dialHTTP2TLS opens a TLS connection and fails unless the server agreed to
+// speak HTTP/2.
+func dialHTTP2TLS(ctx context.Context, dialer *net.Dialer, network, addr string, cfg *tls.Config) (net.Conn, error) {
+	tlsDialer := &tls.Dialer{NetDialer: dialer, Config: cfg}
+	conn, err := tlsDialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if proto := conn.(*tls.Conn).ConnectionState().NegotiatedProtocol; proto != http2.NextProtoTLS {
+		conn.Close()
+		if proto == "" {
+			proto = "http/1.1"
+		}
+		return nil, fmt.Errorf("%s does not support HTTP/2 (negotiated %s)", addr, proto)
+	}
+	return conn, nil
+}
+
+func (t *http2OnlyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := t.tls
+	if req.URL.Scheme == "http" {
+		rt = t.h2c
+	}
+	resp, err := rt.RoundTrip(req)
+	if err == nil && resp.ProtoMajor != 2 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s answered with %s, not HTTP/2", req.URL.Host, resp.Proto)
+	}
+	return resp, err
+}
@@ -0,0 +1,53 @@
+package ocr
+
+import (
+	"crypto/tls"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// forcedHTTP2Client returns a client with the forced HTTP/2 transport that
+// trusts srv's certificate.
+func forcedHTTP2Client(srv *httptest.Server) *http.Client {
+	roots := srv.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	return &http.Client{Transport: newHTTP2Transport(&net.Dialer{}, &tls.Config{RootCAs: roots})}
+}
+
+func TestHTTP2TransportNegotiatesH2(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	resp, err := forcedHTTP2Client(srv).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Proto = %s, want HTTP/2", resp.Proto)
+	}
+}
+
+func TestHTTP2TransportRejectsHTTP1Server(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	// Depending on the server, the mismatch fails the TLS handshake or
+	// negotiates HTTP/1.1; either way the request must not go through.
+	resp, err := forcedHTTP2Client(srv).Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("Get succeeded over %s, want an error", resp.Proto)
+	}
+}
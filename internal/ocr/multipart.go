@@ -0,0 +1,48 @@
+package ocr
+
+import (
+	"encoding/json"
+	"io"
+	"mime/multipart"
+)
+
+// multipartBody returns a factory for a body that streams the raw file in
+// the fileField form field alongside the JSON-encoded options in an "options" field,
+// plus its content type. Each call of the factory yields a fresh body with
+// the same boundary, so requests can be retried.
+func multipartBody(fileField, fileName string, data []byte, options map[string]interface{}) (func() io.ReadCloser, string) {
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+
+	newBody := func() io.ReadCloser {
+		pr, pw := io.Pipe()
+		writer := multipart.NewWriter(pw)
+		writer.SetBoundary(boundary)
+
+		go func() {
+			pw.CloseWithError(writeMultipart(writer, fileField, fileName, data, options))
+		}()
+		return pr
+	}
+
+	return newBody, "multipart/form-data; boundary=" + boundary
+}
+
+func writeMultipart(writer *multipart.Writer, fileField, fileName string, data []byte, options map[string]interface{}) error {
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteField("options", string(optionsJSON)); err != nil {
+		return err
+	}
+
+	part, err := writer.CreateFormFile(fileField, fileName)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(data); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
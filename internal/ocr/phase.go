@@ -0,0 +1,63 @@
+package ocr
+
+import (
+	"context"
+	"net/http/httptrace"
+)
+
+// Phase is a stage of an OCR request, reported through OCROptions.OnPhase.
+type Phase int
+
+const (
+	// PhaseEncoding covers preparing the upload: decrypting, downscaling
+	// and encoding the file.
+	PhaseEncoding Phase = iota
+	// PhaseUploading starts when a connection is ready and the request is
+	// being sent; it is reported again for each retry.
+	PhaseUploading
+	// PhaseWaiting starts once the whole request is sent.
+	PhaseWaiting
+	// PhaseParsing covers reading and decoding the response.
+	PhaseParsing
+)
+
+// String returns the phase's name.
+func (p Phase) String() string {
+	switch p {
+	case PhaseEncoding:
+		return "encoding"
+	case PhaseUploading:
+		return "uploading"
+	case PhaseWaiting:
+		return "waiting"
+	case PhaseParsing:
+		return "parsing"
+	}
+	return "unknown"
+}
+
+// reportPhase calls opts.OnPhase if it is set.
+func (opts OCROptions) reportPhase(phase Phase, size int64) {
+	if opts.OnPhase != nil {
+		opts.OnPhase(phase, size)
+	}
+}
+
+// withPhaseTrace returns ctx with a trace reporting PhaseUploading of size
+// bytes when each attempt has its connection and PhaseWaiting once the
+// request is written, or ctx itself when opts.OnPhase is unset.
+func withPhaseTrace(ctx context.Context, opts OCROptions, size int64) context.Context {
+	if opts.OnPhase == nil {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			opts.OnPhase(PhaseUploading, size)
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				opts.OnPhase(PhaseWaiting, 0)
+			}
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package ocr
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+)
+
+// TOTPCode returns the current RFC 6238 code (30-second period, 6 digits)
+// for a base32 secret.
+func TOTPCode(secret string) (string, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(secret), " ", ""))
+	return totp.GenerateCode(secret, time.Now())
+}
+
+// authorization returns the Authorization header value for token: the
+// token itself, followed by ":" and the current TOTP code when a TOTP
+// secret is configured.
+func (c *Client) authorization(token string) string {
+	secret := c.config.PaddleOCR.TOTPSecret
+	if secret == "" {
+		return "token " + token
+	}
+	code, err := TOTPCode(secret)
+	if err != nil {
+		c.debugf("Cannot generate TOTP code: %v", err)
+		return "token " + token
+	}
+	return "token " + token + ":" + code
+}
@@ -0,0 +1,97 @@
+// Package v1 implements the original PaddleOCR layout parsing wire format,
+// which uses camelCase field names.
+package v1
+
+import (
+	"encoding/json"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr/api"
+)
+
+// Version is the api_version value selecting this format.
+const Version = "v1"
+
+// Encoder encodes v1 requests.
+type Encoder struct{}
+
+// Encode implements api.Encoder. Feature names are the v1 field names.
+func (Encoder) Encode(req *api.Request) map[string]interface{} {
+	payload := map[string]interface{}{
+		"fileType": req.FileType,
+	}
+	for name, enabled := range req.Features {
+		payload[name] = enabled
+	}
+	return payload
+}
+
+// FileField implements api.Encoder.
+func (Encoder) FileField() string {
+	return "file"
+}
+
+// Decoder decodes v1 responses.
+type Decoder struct{}
+
+// Decode implements api.Decoder.
+func (Decoder) Decode(body []byte) (*api.Response, error) {
+	var response struct {
+		LogID     string `json:"logId"`
+		ErrorCode int    `json:"errorCode"`
+		ErrorMsg  string `json:"errorMsg"`
+		Result    struct {
+			LayoutParsingResults []struct {
+				Markdown struct {
+					Text   string            `json:"text"`
+					Images map[string]string `json:"images"`
+				} `json:"markdown"`
+				PageIndex    *int `json:"pageIndex"`
+				PrunedResult struct {
+					PageIndex *int `json:"page_index"`
+				} `json:"prunedResult"`
+			} `json:"layoutParsingResults"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+
+	result := &api.Response{
+		LogID:     response.LogID,
+		ErrorCode: response.ErrorCode,
+		ErrorMsg:  response.ErrorMsg,
+	}
+	for _, layoutResult := range response.Result.LayoutParsingResults {
+		index := layoutResult.PageIndex
+		if index == nil {
+			index = layoutResult.PrunedResult.PageIndex
+		}
+		result.Pages = append(result.Pages, api.Page{
+			Markdown: layoutResult.Markdown.Text,
+			Images:   layoutResult.Markdown.Images,
+			Index:    index,
+		})
+	}
+	return result, nil
+}
+
+// DecodeHeader implements api.Decoder.
+func (Decoder) DecodeHeader(body []byte) (*api.Response, int, error) {
+	var response struct {
+		LogID     string `json:"logId"`
+		ErrorCode int    `json:"errorCode"`
+		ErrorMsg  string `json:"errorMsg"`
+		Result    struct {
+			// Empty structs take no memory, whatever the count.
+			LayoutParsingResults []struct{} `json:"layoutParsingResults"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, 0, err
+	}
+	return &api.Response{
+		LogID:     response.LogID,
+		ErrorCode: response.ErrorCode,
+		ErrorMsg:  response.ErrorMsg,
+	}, len(response.Result.LayoutParsingResults), nil
+}
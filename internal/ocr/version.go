@@ -0,0 +1,50 @@
+package ocr
+
+import (
+	"context"
+
+	"github.com/Explorer1092/paddleocr_cli/internal/ocr/api"
+	v1 "github.com/Explorer1092/paddleocr_cli/internal/ocr/v1"
+	v2 "github.com/Explorer1092/paddleocr_cli/internal/ocr/v2"
+)
+
+// apiCodec pairs the encoder and decoder of one API version.
+type apiCodec struct {
+	api.Encoder
+	api.Decoder
+}
+
+// codecs maps API versions to their wire formats.
+var codecs = map[string]apiCodec{
+	v1.Version: {v1.Encoder{}, v1.Decoder{}},
+	v2.Version: {v2.Encoder{}, v2.Decoder{}},
+}
+
+// codec returns the wire format for the client's API version, resolving it
+// once per client, with the request fields renamed as configured.
+func (c *Client) codec(ctx context.Context) apiCodec {
+	c.codecOnce.Do(func() {
+		version := c.resolveAPIVersion(ctx)
+		c.debugf("Using API version %s", version)
+		c.apiCodec = codecs[version]
+		if names := c.config.PaddleOCR.RequestFields; len(names) > 0 {
+			c.debugf("Renaming request fields: %v", names)
+			c.apiCodec.Encoder = renamingEncoder{Encoder: c.apiCodec.Encoder, names: names}
+		}
+	})
+	return c.apiCodec
+}
+
+// resolveAPIVersion returns the configured API version, or the version the
+// server advertises, or v1.
+func (c *Client) resolveAPIVersion(ctx context.Context) string {
+	if _, ok := codecs[c.APIVersion]; ok {
+		return c.APIVersion
+	}
+	if capabilities, err := c.FetchCapabilities(ctx); err == nil {
+		if _, ok := codecs[capabilities.APIVersion]; ok {
+			return capabilities.APIVersion
+		}
+	}
+	return v1.Version
+}
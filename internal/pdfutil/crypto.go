@@ -0,0 +1,157 @@
+// Package pdfutil provides PDF helpers used before uploading documents.
+package pdfutil
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var (
+	// ErrPasswordRequired is returned when an encrypted PDF needs a password.
+	ErrPasswordRequired = errors.New("PDF is encrypted and requires a password")
+	// ErrWrongPassword is returned when the supplied PDF password is incorrect.
+	ErrWrongPassword = errors.New("incorrect PDF password")
+)
+
+func init() {
+	// Keep pdfcpu from creating its own config directory.
+	api.DisableConfigDir()
+}
+
+// IsEncrypted reports whether the PDF's trailer references an encryption
+// dictionary. Only the trailer is checked, so documents that merely contain
+// the bytes "/Encrypt" elsewhere are not mistaken for encrypted ones.
+func IsEncrypted(data []byte) bool {
+	return hasKey(trailer(data), "/Encrypt")
+}
+
+// trailer returns the trailer dictionary of the last cross-reference
+// section: the dictionary after the "trailer" keyword of an xref table, or
+// the dictionary of an xref stream. Files whose startxref offset is damaged
+// fall back to the last "trailer" keyword. It returns nil when no trailer is
+// found.
+func trailer(data []byte) []byte {
+	if i := bytes.LastIndex(data, []byte("startxref")); i >= 0 {
+		fields := bytes.Fields(data[i+len("startxref"):])
+		if len(fields) > 0 {
+			offset, err := strconv.Atoi(string(fields[0]))
+			if err == nil && offset >= 0 && offset < len(data) {
+				section := bytes.TrimLeft(data[offset:], " \t\r\n")
+				if !bytes.HasPrefix(section, []byte("xref")) {
+					// An xref stream: its dictionary is the trailer.
+					return dictionary(section)
+				}
+				if t := bytes.Index(section, []byte("trailer")); t >= 0 {
+					return dictionary(section[t+len("trailer"):])
+				}
+			}
+		}
+	}
+	if t := bytes.LastIndex(data, []byte("trailer")); t >= 0 {
+		return dictionary(data[t+len("trailer"):])
+	}
+	return nil
+}
+
+// dictionary returns the first dictionary in data, from "<<" through the
+// matching ">>", skipping over nested dictionaries and strings. It returns
+// nil when the dictionary is not closed.
+func dictionary(data []byte) []byte {
+	start := bytes.Index(data, []byte("<<"))
+	if start < 0 {
+		return nil
+	}
+
+	depth := 0
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '<':
+			if i+1 < len(data) && data[i+1] == '<' {
+				depth++
+				i++
+				continue
+			}
+			// A hex string.
+			end := bytes.IndexByte(data[i:], '>')
+			if end < 0 {
+				return nil
+			}
+			i += end
+		case '>':
+			if i+1 < len(data) && data[i+1] == '>' {
+				depth--
+				i++
+				if depth == 0 {
+					return data[start : i+1]
+				}
+			}
+		case '(':
+			i = skipString(data, i)
+		}
+	}
+	return nil
+}
+
+// skipString returns the index of the parenthesis closing the literal
+// string that starts at data[start].
+func skipString(data []byte, start int) int {
+	depth := 0
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(data)
+}
+
+// hasKey reports whether dict holds the name key, as opposed to a longer
+// name that starts with it (e.g. /EncryptMetadata).
+func hasKey(dict []byte, key string) bool {
+	for rest := dict; ; {
+		i := bytes.Index(rest, []byte(key))
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(key):]
+		if len(rest) == 0 || strings.IndexByte(" \t\r\n/<[(", rest[0]) >= 0 {
+			return true
+		}
+	}
+}
+
+// Decrypt returns a decrypted copy of an encrypted PDF. The result is kept in
+// memory only. Data that turns out not to be encrypted is returned unchanged.
+func Decrypt(data []byte, password string) ([]byte, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = password
+	conf.OwnerPW = password
+
+	var buf bytes.Buffer
+	if err := api.Decrypt(bytes.NewReader(data), &buf, conf); err != nil {
+		if errors.Is(err, pdfcpu.ErrWrongPassword) {
+			if password == "" {
+				return nil, ErrPasswordRequired
+			}
+			return nil, ErrWrongPassword
+		}
+		if strings.Contains(err.Error(), "not encrypted") {
+			return data, nil
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,54 @@
+package pdfutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+// classicPDF builds a minimal PDF with an xref table and the given trailer
+// entries, plus body bytes placed in an object.
+func classicPDF(body, trailerEntries string) []byte {
+	head := "%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n2 0 obj\n(" + body + ")\nendobj\n"
+	xref := len(head)
+	return []byte(fmt.Sprintf("%sxref\n0 3\ntrailer\n<< /Size 3 /Root 1 0 R%s >>\nstartxref\n%d\n%%%%EOF\n", head, trailerEntries, xref))
+}
+
+// streamPDF builds a minimal PDF whose trailer is an xref stream dictionary.
+func streamPDF(trailerEntries string) []byte {
+	head := "%PDF-1.5\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"
+	xref := len(head)
+	return []byte(fmt.Sprintf("%s3 0 obj\n<< /Type /XRef /Size 4 /ID [<a1b2><c3d4>]%s >>\nstream\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", head, trailerEntries, xref))
+}
+
+func TestIsEncrypted(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"plain", classicPDF("hello", ""), false},
+		{"encrypted", classicPDF("hello", " /Encrypt 5 0 R"), true},
+		{"encrypt in body only", classicPDF("see /Encrypt 5 0 R", ""), false},
+		{"longer name in trailer", classicPDF("hello", " /EncryptMetadata false"), false},
+		{"encrypted xref stream", streamPDF(" /Encrypt 5 0 R"), true},
+		{"plain xref stream", streamPDF(""), false},
+		{"damaged startxref", []byte("%PDF-1.4\ntrailer\n<< /Encrypt 5 0 R >>\nstartxref\n99999\n%%EOF\n"), true},
+		{"no trailer", []byte("%PDF-1.4\n/Encrypt\n"), false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsEncrypted(tt.data); got != tt.want {
+				t.Errorf("IsEncrypted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictionarySkipsStrings(t *testing.T) {
+	data := []byte("<< /A (a >> b) /B <ab> /C << /D 1 >> >> trailing")
+	want := "<< /A (a >> b) /B <ab> /C << /D 1 >> >>"
+	if got := string(dictionary(data)); got != want {
+		t.Errorf("dictionary() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,28 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FreeSpace returns the bytes available to the current user on the volume
+// that holds path. path need not exist yet: its nearest existing parent is
+// measured. On systems where this is not supported, it returns an error
+// wrapping errors.ErrUnsupported.
+func FreeSpace(path string) (uint64, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return freeSpace(dir)
+}
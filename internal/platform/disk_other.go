@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+func freeSpace(dir string) (uint64, error) {
+	return 0, fmt.Errorf("measuring free space on %s: %w", runtime.GOOS, errors.ErrUnsupported)
+}
@@ -0,0 +1,15 @@
+package platform
+
+import "golang.org/x/sys/windows"
+
+func freeSpace(dir string) (uint64, error) {
+	name, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+	var available uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &available, nil, nil); err != nil {
+		return 0, err
+	}
+	return available, nil
+}
@@ -0,0 +1,30 @@
+// Package platform wraps operating-system facilities that differ between
+// Linux, macOS and Windows.
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// OpenFile opens path in the user's default application for its type,
+// with xdg-open on Linux and other Unix systems, open on macOS and start
+// on Windows. It returns once the opener has been started.
+func OpenFile(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		// The empty argument is start's window title; without it a quoted
+		// path would be taken as the title.
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("cannot run %s: %w", cmd.Args[0], err)
+	}
+	return cmd.Process.Release()
+}
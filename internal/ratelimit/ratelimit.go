@@ -0,0 +1,46 @@
+// Package ratelimit interprets server rate-limit responses.
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// now is replaceable so delays can be computed against a fixed clock.
+var now = time.Now
+
+// ParseRetryAfter returns how long a rate-limited client should wait before
+// retrying, or 0 if the response does not say. Retry-After is honoured in
+// both its delay-seconds ("60") and HTTP-date forms; otherwise
+// X-RateLimit-Reset is read as a Unix timestamp, or as seconds to wait when
+// the value is too small to be one. Times in the past yield 0.
+func ParseRetryAfter(h http.Header) time.Duration {
+	if value := strings.TrimSpace(h.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return positive(time.Duration(seconds) * time.Second)
+		}
+		if t, err := http.ParseTime(value); err == nil {
+			return positive(t.Sub(now()))
+		}
+	}
+
+	if value := strings.TrimSpace(h.Get("X-RateLimit-Reset")); value != "" {
+		if reset, err := strconv.ParseInt(value, 10, 64); err == nil {
+			// Unix timestamps are far larger than any sensible delay.
+			if reset > 1e9 {
+				return positive(time.Unix(reset, 0).Sub(now()))
+			}
+			return positive(time.Duration(reset) * time.Second)
+		}
+	}
+	return 0
+}
+
+func positive(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
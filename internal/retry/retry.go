@@ -0,0 +1,85 @@
+// Package retry provides helpers for retrying transient failures.
+package retry
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// InitialBackoff is the delay before the first retry. It doubles after
+// each further attempt.
+const InitialBackoff = time.Second
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that RetryWithBudget returns it without retrying.
+// It returns nil for a nil err.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// delayedError asks for the next retry to wait a specific time.
+type delayedError struct {
+	err  error
+	wait time.Duration
+}
+
+func (e *delayedError) Error() string { return e.err.Error() }
+func (e *delayedError) Unwrap() error { return e.err }
+
+// After wraps err so that RetryWithBudget waits d, instead of the current
+// backoff, before the next attempt. It returns nil for a nil err.
+func After(err error, d time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &delayedError{err: err, wait: d}
+}
+
+// RetryWithBudget calls fn until it succeeds, returns an error wrapped with
+// Permanent, or the budget is exhausted, backing off exponentially between
+// attempts unless an error wrapped with After asks for a specific wait. The
+// budget covers the whole sequence, including the attempts themselves; no
+// retry is started if its wait would overrun it. The last error is returned,
+// unwrapped, or ctx's error if it is cancelled while waiting.
+func RetryWithBudget(ctx context.Context, budget time.Duration, fn func() error) error {
+	deadline := time.Now().Add(budget)
+	backoff := InitialBackoff
+	for {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		wait := backoff
+		var delayed *delayedError
+		if errors.As(err, &delayed) {
+			wait, err = delayed.wait, delayed.err
+		}
+		if time.Until(deadline) < wait {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,370 @@
+// Package update finds, verifies and installs new releases published on
+// GitHub.
+package update
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// LatestReleaseURL is the GitHub API endpoint for the newest release.
+	LatestReleaseURL = "https://api.github.com/repos/Explorer1092/paddleocr_cli/releases/latest"
+	// ChecksumsAsset is the release asset listing the SHA-256 of the others.
+	ChecksumsAsset = "checksums.txt"
+	// TokenEnvVar holds a GitHub token sent with API requests, which raises
+	// the rate limit for shared or CI IP addresses.
+	TokenEnvVar = "GITHUB_TOKEN"
+
+	binaryName = "paddleocr-cli"
+	// maxDownload bounds the size of a downloaded asset.
+	maxDownload = 200 << 20
+)
+
+// ErrNotFound is returned when a release has no asset for a platform.
+var ErrNotFound = errors.New("release asset not found")
+
+// Release is a published GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release's version without the leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the asset with the given name.
+func (r *Release) Asset(name string) (Asset, error) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, nil
+		}
+	}
+	return Asset{}, fmt.Errorf("%w: %s in %s", ErrNotFound, name, r.TagName)
+}
+
+// Latest fetches the newest release from url, normally LatestReleaseURL.
+func Latest(client *http.Client, url string) (*Release, error) {
+	body, err := get(client, url, "application/vnd.github+json")
+	if err != nil {
+		return nil, err
+	}
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("invalid release data: %v", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("invalid release data: no tag name")
+	}
+	return &release, nil
+}
+
+// Download fetches a release asset.
+func Download(client *http.Client, asset Asset) ([]byte, error) {
+	return get(client, asset.URL, "application/octet-stream")
+}
+
+func get(client *http.Client, url, accept string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if token := os.Getenv(TokenEnvVar); token != "" && strings.HasPrefix(url, "https://api.github.com/") {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: HTTP %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("GET %s: response larger than %d MB", url, maxDownload>>20)
+	}
+	return data, nil
+}
+
+// ArchiveName returns the name of the release archive for a platform, as
+// produced by the release build.
+func ArchiveName(goos, goarch string) string {
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("%s_%s_%s%s", binaryName, goos, goarch, ext)
+}
+
+// VerifyChecksum checks data against the SHA-256 listed for name in a
+// checksums file ("HASH  NAME" per line).
+func VerifyChecksum(checksums []byte, name string, data []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("checksum mismatch for %s", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum listed for %s", name)
+}
+
+// ExtractBinary returns the executable from a release archive.
+func ExtractBinary(archive []byte, archiveName string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		return extractZip(archive, binaryName+".exe")
+	}
+	return extractTarGz(archive, binaryName)
+}
+
+func extractTarGz(archive []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%s not found in archive", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == name {
+			return io.ReadAll(io.LimitReader(tr, maxDownload))
+		}
+	}
+}
+
+func extractZip(archive []byte, name string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range zr.File {
+		if path.Base(file.Name) != name {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(io.LimitReader(rc, maxDownload))
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+// Newer reports whether version latest is newer than current. Versions are
+// dotted numbers with an optional leading "v" and pre-release suffix
+// ("1.2.0-rc1"), which sorts before the release itself (see
+// comparePrerelease).
+func Newer(latest, current string) (bool, error) {
+	l, lpre, err := parseVersion(latest)
+	if err != nil {
+		return false, err
+	}
+	c, cpre, err := parseVersion(current)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i < max(len(l), len(c)); i++ {
+		var a, b int
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(c) {
+			b = c[i]
+		}
+		if a != b {
+			return a > b, nil
+		}
+	}
+	return cpre != "" && (lpre == "" || comparePrerelease(lpre, cpre) > 0), nil
+}
+
+// comparePrerelease orders two pre-release suffixes, returning -1, 0 or +1.
+// Runs of digits compare as numbers, so "rc10" follows "rc9" and "beta.2"
+// precedes "beta.11"; other runs compare as text.
+func comparePrerelease(a, b string) int {
+	for a != "" && b != "" {
+		var x, y string
+		x, a = leadingRun(a)
+		y, b = leadingRun(b)
+		if isDigit(x[0]) && isDigit(y[0]) {
+			x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+			if c := cmp.Compare(len(x), len(y)); c != 0 {
+				return c
+			}
+		}
+		if c := strings.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+// leadingRun splits s after its leading run of digits or of non-digits.
+func leadingRun(s string) (string, string) {
+	digits := isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func parseVersion(version string) ([]int, string, error) {
+	v := strings.TrimPrefix(version, "v")
+	v, _, _ = strings.Cut(v, "+")
+	v, pre, _ := strings.Cut(v, "-")
+
+	var parts []int
+	for _, field := range strings.Split(v, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, "", fmt.Errorf("not a release version: %q", version)
+		}
+		parts = append(parts, n)
+	}
+	return parts, pre, nil
+}
+
+// ManagedPrefixes are install locations owned by package managers, where
+// replacing the binary would fight the package manager.
+var ManagedPrefixes = []string{
+	"/usr/bin/",
+	"/usr/sbin/",
+	"/bin/",
+	"/usr/lib/",
+	"/usr/share/",
+	"/opt/homebrew/",
+	"/usr/local/Cellar/",
+	"/home/linuxbrew/",
+	"/nix/store/",
+	"/snap/",
+}
+
+// IsManaged reports whether the executable at exe, after resolving
+// symlinks, lives under one of ManagedPrefixes.
+func IsManaged(exe string) bool {
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	exe = filepath.ToSlash(exe)
+	for _, prefix := range ManagedPrefixes {
+		if strings.HasPrefix(exe, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Writable checks that Replace can swap exe: the new binary is written
+// next to it and renamed over it, which needs write access to its
+// directory.
+func Writable(exe string) error {
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".new-*")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
+
+// Replace atomically replaces the executable at exe with data. On Windows,
+// where a running executable cannot be overwritten, the current one is
+// first renamed to exe+".old" (removed by the next Replace).
+func Replace(exe string, data []byte, windows bool) (err error) {
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(exe); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".new-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if !windows {
+		return os.Rename(tmp.Name(), exe)
+	}
+
+	old := exe + ".old"
+	os.Remove(old)
+	if err = os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), exe); err != nil {
+		os.Rename(old, exe)
+		return err
+	}
+	return nil
+}
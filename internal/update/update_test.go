@@ -0,0 +1,39 @@
+package update
+
+import "testing"
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"1.2.0", "1.1.9", true},
+		{"v1.10.0", "1.9.0", true},
+		{"1.2", "1.2.0", false},
+		{"1.2.0", "1.2.0", false},
+		{"1.2.0", "1.2.0-rc1", true},
+		{"1.2.0-rc1", "1.2.0", false},
+		{"1.2.0-rc10", "1.2.0-rc9", true},
+		{"1.2.0-rc9", "1.2.0-rc10", false},
+		{"1.2.0-rc2", "1.2.0-rc1", true},
+		{"1.2.0-beta.11", "1.2.0-beta.2", true},
+		{"1.2.0-rc.1", "1.2.0-beta.5", true},
+		{"1.2.0-rc01", "1.2.0-rc1", false},
+		{"1.2.0-rc1.1", "1.2.0-rc1", true},
+		{"1.2.0+build.5", "1.2.0", false},
+	}
+	for _, tt := range tests {
+		got, err := Newer(tt.latest, tt.current)
+		if err != nil {
+			t.Errorf("Newer(%q, %q): %v", tt.latest, tt.current, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+
+	if _, err := Newer("1.2.x", "1.2.0"); err == nil {
+		t.Error("Newer(\"1.2.x\", ...) succeeded, want an error")
+	}
+}